@@ -2,6 +2,7 @@ package check
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/ipfs/go-log/v2"
@@ -15,10 +16,24 @@ type (
 	Checker interface {
 		Check(context.Context, *sample.Set) *Results
 	}
+	// EndpointChecker checks the health of an IPNI endpoint as a whole, independently of sample
+	// sets. Endpoint checkers run once per cycle, even when no samplers produce sets.
+	EndpointChecker interface {
+		CheckEndpoint(context.Context) EndpointResults
+	}
+	// EndpointResults is the outcome of an EndpointChecker run, the concrete type of which depends
+	// on the checker.
+	EndpointResults interface {
+		// Checker returns the name of the checker that produced the results.
+		Checker() string
+	}
 	Results struct {
 		Results       []*Result
 		SampleSetName string
 		CheckerName   string
+		// LookupPaths are the lookup API paths via which the checker performs checks, regardless
+		// of whether any results were produced.
+		LookupPaths []LookupPath
 	}
 	Result struct {
 		Multihash  multihash.Multihash
@@ -27,5 +42,58 @@ type (
 		Timeout    time.Duration
 		Elapsed    time.Duration
 		Streaming  bool
+		// LookupPath is the lookup API path via which the check was performed.
+		LookupPath LookupPath
+		// TimeToFirstProvider is the time it took to receive the first provider record when the
+		// check is streaming. Elapsed is then the time it took to reach the end of stream.
+		TimeToFirstProvider time.Duration
+		// ProviderCount is the number of provider records returned for the multihash.
+		ProviderCount int
+		// PeerIDs is the distinct set of provider peer IDs returned for the multihash.
+		PeerIDs []string
+		// ProvidersByProtocol is the number of providers returned per transfer protocol, keyed by
+		// one of ProtocolBitswap, ProtocolGraphsync, ProtocolHttp or ProtocolOther. It is only
+		// populated by checkers that can tell transfer protocols apart.
+		ProvidersByProtocol map[string]int
+		// Retrievals is the outcome of probing each returned provider for retrievability, keyed by
+		// provider peer ID. It is only populated by RetrievabilityChecker.
+		Retrievals map[string][]*Retrieval
+		// Comparisons is the outcome of comparing the provider records returned by every pair of
+		// endpoints. It is only populated by ConsistencyChecker.
+		Comparisons []*Comparison
+		// DecodeErr is the error that occurred while decoding the response body, if any.
+		DecodeErr error
+	}
+	// Retrieval is the outcome of probing a provider for retrievability over a transfer protocol.
+	Retrieval struct {
+		Protocol    string
+		Retrievable bool
+		Err         error
+		Elapsed     time.Duration
 	}
 )
+
+// Succeeded checks whether the lookup produced a usable answer, i.e. a successful response that
+// was decoded without error and contains at least one provider record for the multihash.
+func (r *Result) Succeeded() bool {
+	return r.Err == nil &&
+		r.DecodeErr == nil &&
+		r.StatusCode == http.StatusOK &&
+		r.ProviderCount > 0
+}
+
+// setProviders populates the provider count and distinct peer IDs from the given records.
+func (r *Result) setProviders(prs []providerResult) {
+	r.ProviderCount = len(prs)
+	r.PeerIDs = nil
+	seen := make(map[string]struct{}, len(prs))
+	for _, pr := range prs {
+		if pr.Provider == nil || pr.Provider.ID == "" {
+			continue
+		}
+		if _, ok := seen[pr.Provider.ID]; !ok {
+			seen[pr.Provider.ID] = struct{}{}
+			r.PeerIDs = append(r.PeerIDs, pr.Provider.ID)
+		}
+	}
+}
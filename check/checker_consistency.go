@@ -0,0 +1,157 @@
+package check
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipni/lookout/sample"
+)
+
+var _ Checker = (*ConsistencyChecker)(nil)
+
+type (
+	// ConsistencyChecker looks up each CID against two or more IPNI endpoints via the non-streaming
+	// find API, and compares the returned provider records across every pair of endpoints.
+	//
+	// The result of each check reflects the lookup against the first endpoint, extended with the
+	// outcome of pairwise comparisons in Result.Comparisons.
+	ConsistencyChecker struct {
+		*options
+		endpoints []*comparedEndpoint
+	}
+	comparedEndpoint struct {
+		name string
+		*options
+	}
+	// Comparison is the difference between the provider records returned by two endpoints for the
+	// same multihash. Providers are identified by their peer ID and context ID.
+	Comparison struct {
+		EndpointA string `json:"endpointA"`
+		EndpointB string `json:"endpointB"`
+		// OnlyInA is the distinct peer IDs of providers only returned by EndpointA.
+		OnlyInA []string `json:"onlyInA,omitempty"`
+		// OnlyInB is the distinct peer IDs of providers only returned by EndpointB.
+		OnlyInB []string `json:"onlyInB,omitempty"`
+		// MetadataMismatch is the distinct peer IDs of providers returned by both endpoints but
+		// with different metadata.
+		MetadataMismatch []string `json:"metadataMismatch,omitempty"`
+	}
+)
+
+func NewConsistencyChecker(o ...Option) (*ConsistencyChecker, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.comparedEndpoints) < 2 {
+		return nil, errors.New("at least two endpoints must be specified for comparison")
+	}
+	c := &ConsistencyChecker{
+		options: opts,
+	}
+	for _, ce := range opts.comparedEndpoints {
+		eopts := *opts
+		eopts.ipniEndpoint = ce.endpoint
+		c.endpoints = append(c.endpoints, &comparedEndpoint{
+			name:    ce.name,
+			options: &eopts,
+		})
+	}
+	return c, nil
+}
+
+func (c *ConsistencyChecker) Check(ctx context.Context, set *sample.Set) *Results {
+	return c.checkInParallel(ctx, set, func(ctx context.Context, target cid.Cid, lp LookupPath) *Result {
+		results := make([]*Result, len(c.endpoints))
+		providers := make([][]providerResult, len(c.endpoints))
+		var wg sync.WaitGroup
+		for i, endpoint := range c.endpoints {
+			wg.Add(1)
+			go func(i int, endpoint *comparedEndpoint) {
+				defer wg.Done()
+				results[i], providers[i] = endpoint.find(ctx, target, lp)
+			}(i, endpoint)
+		}
+		wg.Wait()
+
+		result := results[0]
+		for a := 0; a < len(c.endpoints); a++ {
+			for b := a + 1; b < len(c.endpoints); b++ {
+				if !isComparable(results[a]) || !isComparable(results[b]) {
+					continue
+				}
+				cmp := compareProviders(providers[a], providers[b])
+				cmp.EndpointA = c.endpoints[a].name
+				cmp.EndpointB = c.endpoints[b].name
+				result.Comparisons = append(result.Comparisons, cmp)
+			}
+		}
+		return result
+	})
+}
+
+// Agrees checks whether both endpoints returned the same providers with the same metadata.
+func (c *Comparison) Agrees() bool {
+	return len(c.OnlyInA) == 0 && len(c.OnlyInB) == 0 && len(c.MetadataMismatch) == 0
+}
+
+// isComparable checks whether the given result reflects a definitive answer from the endpoint, i.e.
+// either a successfully decoded response or not found.
+func isComparable(r *Result) bool {
+	return r.Err == nil && r.DecodeErr == nil &&
+		(r.StatusCode == http.StatusOK || r.StatusCode == http.StatusNotFound)
+}
+
+func compareProviders(a, b []providerResult) *Comparison {
+	type key struct{ peerID, contextID string }
+	index := func(prs []providerResult) map[key][]byte {
+		m := make(map[key][]byte, len(prs))
+		for _, pr := range prs {
+			if pr.Provider == nil {
+				continue
+			}
+			m[key{peerID: pr.Provider.ID, contextID: string(pr.ContextID)}] = pr.Metadata
+		}
+		return m
+	}
+	ia, ib := index(a), index(b)
+	onlyInA := make(map[string]struct{})
+	onlyInB := make(map[string]struct{})
+	mismatch := make(map[string]struct{})
+	for k, amd := range ia {
+		bmd, ok := ib[k]
+		switch {
+		case !ok:
+			onlyInA[k.peerID] = struct{}{}
+		case !bytes.Equal(amd, bmd):
+			mismatch[k.peerID] = struct{}{}
+		}
+	}
+	for k := range ib {
+		if _, ok := ia[k]; !ok {
+			onlyInB[k.peerID] = struct{}{}
+		}
+	}
+	return &Comparison{
+		OnlyInA:          sortedKeys(onlyInA),
+		OnlyInB:          sortedKeys(onlyInB),
+		MetadataMismatch: sortedKeys(mismatch),
+	}
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,153 @@
+package check
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipni/lookout/sample"
+)
+
+var _ Checker = (*DelegatedRoutingChecker)(nil)
+
+const (
+	ProtocolBitswap   = "bitswap"
+	ProtocolGraphsync = "graphsync"
+	ProtocolHttp      = "http"
+	ProtocolOther     = "other"
+)
+
+type (
+	// DelegatedRoutingChecker checks lookups via the HTTP delegated routing API, i.e.
+	// /routing/v1/providers/{cid}, and counts the returned providers by transfer protocol.
+	// Both the JSON and the NDJSON response variants are supported; the latter is requested when
+	// the checker is configured WithStreaming.
+	DelegatedRoutingChecker struct {
+		*options
+	}
+)
+
+func NewDelegatedRoutingChecker(o ...Option) (*DelegatedRoutingChecker, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	opts.lookupPaths = []LookupPath{LookupPathRoutingProviders}
+	return &DelegatedRoutingChecker{
+		options: opts,
+	}, nil
+}
+
+func (c *DelegatedRoutingChecker) Check(ctx context.Context, set *sample.Set) *Results {
+	return c.checkInParallel(ctx, set, func(ctx context.Context, target cid.Cid, lp LookupPath) *Result {
+		result := &Result{
+			Multihash:  target.Hash(),
+			Timeout:    c.checkTimeout,
+			LookupPath: lp,
+			Streaming:  c.streaming,
+		}
+		start := time.Now()
+		cctx, cancel := context.WithTimeout(ctx, c.checkTimeout)
+		defer cancel()
+		path := c.ipniEndpoint.JoinPath("routing", "v1", "providers", target.String())
+		request, err := http.NewRequestWithContext(cctx, http.MethodGet, path.String(), nil)
+		if err != nil {
+			logger.Errorw("Failed to instantiate HTTP request", "err", err)
+			result.Err = err
+			return result
+		}
+		if c.streaming {
+			request.Header.Add("Accept", "application/x-ndjson")
+		} else {
+			request.Header.Add("Accept", "application/json")
+		}
+		resp, err := c.httpClient.Do(request)
+		if err != nil {
+			logger.Errorw("Failed to perform HTTP call", "err", err)
+			result.Err = err
+			return result
+		}
+		defer resp.Body.Close()
+		result.StatusCode = resp.StatusCode
+		if resp.StatusCode != http.StatusOK {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			result.Elapsed = time.Since(start)
+			return result
+		}
+
+		var records []routingProviderRecord
+		// Servers may respond with either variant regardless of what was asked for; go by the
+		// content type of the response.
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if mediaType == "application/x-ndjson" {
+			scanner := bufio.NewScanner(resp.Body)
+			scanner.Buffer(make([]byte, 0, 4096), maxNdjsonLineSize)
+			for scanner.Scan() {
+				line := bytes.TrimSpace(scanner.Bytes())
+				if len(line) == 0 {
+					continue
+				}
+				var record routingProviderRecord
+				if err := json.Unmarshal(line, &record); err != nil {
+					logger.Warnw("Failed to decode streaming provider record", "err", err)
+					result.DecodeErr = err
+					continue
+				}
+				if result.TimeToFirstProvider == 0 {
+					result.TimeToFirstProvider = time.Since(start)
+				}
+				records = append(records, record)
+			}
+			if err := scanner.Err(); err != nil {
+				logger.Errorw("Failed to read streaming response", "err", err)
+				result.Err = err
+			}
+		} else {
+			var rpr routingProvidersResponse
+			if err := json.NewDecoder(resp.Body).Decode(&rpr); err != nil {
+				logger.Warnw("Failed to decode delegated routing response", "err", err)
+				result.DecodeErr = err
+			}
+			records = rpr.Providers
+		}
+
+		prs := make([]providerResult, 0, len(records))
+		result.ProvidersByProtocol = make(map[string]int)
+		for _, record := range records {
+			prs = append(prs, providerResult{
+				Provider: &addrInfo{ID: record.ID, Addrs: record.Addrs},
+			})
+			protocols := record.Protocols
+			if record.Protocol != "" {
+				protocols = append(protocols, record.Protocol)
+			}
+			for _, p := range protocols {
+				result.ProvidersByProtocol[transferProtocol(p)]++
+			}
+		}
+		result.setProviders(prs)
+		result.Elapsed = time.Since(start)
+		return result
+	})
+}
+
+// transferProtocol maps the given delegated routing transfer protocol name to one of
+// ProtocolBitswap, ProtocolGraphsync, ProtocolHttp or ProtocolOther.
+func transferProtocol(name string) string {
+	switch name {
+	case "transport-bitswap":
+		return ProtocolBitswap
+	case "transport-graphsync-filecoinv1":
+		return ProtocolGraphsync
+	case "transport-ipfs-gateway-http":
+		return ProtocolHttp
+	default:
+		return ProtocolOther
+	}
+}
@@ -0,0 +1,138 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	_ EndpointChecker = (*IndexerStatusChecker)(nil)
+	_ EndpointResults = (*IndexerStatusResults)(nil)
+)
+
+type (
+	// IndexerStatusChecker checks the liveness and size of an IPNI endpoint via its health and
+	// stats APIs, i.e. /health and /stats, and optionally counts its providers via /providers.
+	IndexerStatusChecker struct {
+		*options
+	}
+	IndexerStatusResults struct {
+		CheckerName string
+		// Healthy is whether /health responded with 200 OK.
+		Healthy          bool
+		HealthStatusCode int
+		HealthErr        error
+		// HealthElapsed is the time it took for /health to respond, if it did.
+		HealthElapsed time.Duration
+		// EntriesEstimate and EntriesCount are as reported by /stats.
+		EntriesEstimate int64
+		EntriesCount    int64
+		StatsErr        error
+		// ProvidersCounted is whether providers were counted, in which case ProviderCount is the
+		// number of providers listed by /providers.
+		ProvidersCounted bool
+		ProviderCount    int
+		ProvidersErr     error
+	}
+	statsResponse struct {
+		EntriesEstimate int64
+		EntriesCount    int64
+	}
+)
+
+func NewIndexerStatusChecker(o ...Option) (*IndexerStatusChecker, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	return &IndexerStatusChecker{
+		options: opts,
+	}, nil
+}
+
+// Checker returns the name of the checker that produced the results.
+func (r *IndexerStatusResults) Checker() string {
+	return r.CheckerName
+}
+
+func (c *IndexerStatusChecker) CheckEndpoint(ctx context.Context) EndpointResults {
+	results := &IndexerStatusResults{CheckerName: c.name}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		c.checkHealth(ctx, results)
+	}()
+	go func() {
+		defer wg.Done()
+		results.EntriesEstimate, results.EntriesCount, results.StatsErr = c.getStats(ctx)
+	}()
+	if c.countProviders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results.ProviderCount, results.ProvidersErr = c.getProviderCount(ctx)
+			results.ProvidersCounted = true
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func (c *IndexerStatusChecker) checkHealth(ctx context.Context, results *IndexerStatusResults) {
+	cctx, cancel := context.WithTimeout(ctx, c.checkTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(cctx, http.MethodGet, c.ipniEndpoint.JoinPath("health").String(), nil)
+	if err != nil {
+		results.HealthErr = err
+		return
+	}
+	start := time.Now()
+	resp, err := c.httpClient.Do(request)
+	if err != nil {
+		results.HealthErr = err
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	results.HealthElapsed = time.Since(start)
+	results.HealthStatusCode = resp.StatusCode
+	results.Healthy = resp.StatusCode == http.StatusOK
+}
+
+func (c *IndexerStatusChecker) getStats(ctx context.Context) (int64, int64, error) {
+	cctx, cancel := context.WithTimeout(ctx, c.checkTimeout)
+	defer cancel()
+	var stats statsResponse
+	status, decodeErr, err := c.getJSON(cctx, c.ipniEndpoint.JoinPath("stats"), &stats)
+	switch {
+	case err != nil:
+		return 0, 0, err
+	case decodeErr != nil:
+		return 0, 0, decodeErr
+	case status != http.StatusOK:
+		return 0, 0, fmt.Errorf("unsuccessful stats response: %d", status)
+	}
+	return stats.EntriesEstimate, stats.EntriesCount, nil
+}
+
+func (c *IndexerStatusChecker) getProviderCount(ctx context.Context) (int, error) {
+	cctx, cancel := context.WithTimeout(ctx, c.checkTimeout)
+	defer cancel()
+	// Decode providers as empty structs, since only the count matters.
+	var providers []struct{}
+	status, decodeErr, err := c.getJSON(cctx, c.ipniEndpoint.JoinPath("providers"), &providers)
+	switch {
+	case err != nil:
+		return 0, err
+	case decodeErr != nil:
+		return 0, decodeErr
+	case status != http.StatusOK:
+		return 0, fmt.Errorf("unsuccessful providers response: %d", status)
+	}
+	return len(providers), nil
+}
@@ -1,8 +1,12 @@
 package check
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"io"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/ipfs/go-cid"
@@ -10,6 +14,8 @@ import (
 	"github.com/ipni/lookout/sample"
 )
 
+var _ Checker = (*IpniNonStreamingChecker)(nil)
+
 type (
 	IpniNonStreamingChecker struct {
 		*options
@@ -27,43 +33,82 @@ func NewIpniNonStreamingChecker(o ...Option) (*IpniNonStreamingChecker, error) {
 }
 
 func (c *IpniNonStreamingChecker) Check(ctx context.Context, set *sample.Set) *Results {
-	results := &Results{
-		Results:       make([]*Result, 0, len(set.Cids)),
-		SampleSetName: set.Name,
-		CheckerName:   c.name,
+	return c.checkInParallel(ctx, set, func(ctx context.Context, mh cid.Cid, lp LookupPath) *Result {
+		result, _ := c.find(ctx, mh, lp)
+		return result
+	})
+}
+
+// find looks up the given CID via the given lookup path and non-streaming find API. It returns the
+// check result along with the provider records found for the CID multihash.
+func (o *options) find(ctx context.Context, mh cid.Cid, lp LookupPath) (*Result, []providerResult) {
+	result := &Result{
+		Multihash:  mh.Hash(),
+		Timeout:    o.checkTimeout,
+		LookupPath: lp,
 	}
-	rch := perform.InParallel(ctx, c.parallelism, set.Cids, func(ctx context.Context, mh cid.Cid) *Result {
-		result := &Result{
-			Multihash: mh.Hash(),
-			Timeout:   c.checkTimeout,
-		}
-		path := c.ipniEndpoint.JoinPath("cid", mh.String())
-		if len(c.cascadeLabels) != 0 {
-			query := path.Query()
-			for _, label := range c.cascadeLabels {
-				query.Add("cascade", label)
-			}
-			path.RawQuery = query.Encode()
+	start := time.Now()
+	cctx, cancel := context.WithTimeout(ctx, o.checkTimeout)
+	defer cancel()
+	var fr findResponse
+	result.StatusCode, result.DecodeErr, result.Err = o.getJSON(cctx, o.lookupURL(mh, lp), &fr)
+	if result.Err != nil {
+		return result, nil
+	}
+	result.Elapsed = time.Since(start)
+	if result.DecodeErr != nil || result.StatusCode != http.StatusOK {
+		return result, nil
+	}
+	for _, mhr := range fr.MultihashResults {
+		if bytes.Equal(mhr.Multihash, result.Multihash) {
+			result.setProviders(mhr.ProviderResults)
+			return result, mhr.ProviderResults
 		}
-		start := time.Now()
-		cctx, cancel := context.WithTimeout(ctx, c.checkTimeout)
-		defer cancel()
-		request, err := http.NewRequestWithContext(cctx, http.MethodGet, path.String(), nil)
-		if err != nil {
-			logger.Errorw("Failed to instantiate HTTP request", "err", err)
-			result.Err = err
-			return result
+	}
+	return result, nil
+}
+
+// lookupURL returns the URL at which the given CID is looked up via the given lookup path,
+// including any cascade labels.
+func (o *options) lookupURL(c cid.Cid, lp LookupPath) *url.URL {
+	var path *url.URL
+	switch lp {
+	case LookupPathMultihash:
+		path = o.ipniEndpoint.JoinPath("multihash", c.Hash().B58String())
+	default:
+		path = o.ipniEndpoint.JoinPath("cid", c.String())
+	}
+	if len(o.cascadeLabels) != 0 {
+		query := path.Query()
+		for _, label := range o.cascadeLabels {
+			query.Add("cascade", label)
 		}
-		request.Header.Add("Accept", "application/json")
-		resp, err := http.DefaultClient.Do(request)
-		if err != nil {
-			logger.Errorw("Failed to perform HTTP call", "err", err)
-			result.Err = err
-			return result
+		path.RawQuery = query.Encode()
+	}
+	return path
+}
+
+// checkInParallel performs the given lookup for every CID in the set via every configured lookup
+// path, respecting the configured parallelism, and gathers the results.
+func (o *options) checkInParallel(ctx context.Context, set *sample.Set, lookup func(context.Context, cid.Cid, LookupPath) *Result) *Results {
+	type target struct {
+		cid  cid.Cid
+		path LookupPath
+	}
+	targets := make([]target, 0, len(set.Cids)*len(o.lookupPaths))
+	for _, c := range set.Cids {
+		for _, lp := range o.lookupPaths {
+			targets = append(targets, target{cid: c, path: lp})
 		}
-		result.Elapsed = time.Since(start)
-		result.StatusCode = resp.StatusCode
-		return result
+	}
+	results := &Results{
+		Results:       make([]*Result, 0, len(targets)),
+		SampleSetName: set.Name,
+		CheckerName:   o.name,
+		LookupPaths:   o.lookupPaths,
+	}
+	rch := perform.InParallel(ctx, o.parallelism, targets, func(ctx context.Context, t target) *Result {
+		return lookup(ctx, t.cid, t.path)
 	})
 	for {
 		select {
@@ -77,3 +122,30 @@ func (c *IpniNonStreamingChecker) Check(ctx context.Context, set *sample.Set) *R
 		}
 	}
 }
+
+// getJSON performs a GET request to the given URL and decodes the JSON response into v when the
+// response status is 200. It returns the response status code, the error that occurred while
+// decoding the response if any, and the error that occurred while performing the request if any.
+func (o *options) getJSON(ctx context.Context, u *url.URL, v any) (int, error, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		logger.Errorw("Failed to instantiate HTTP request", "err", err)
+		return 0, nil, err
+	}
+	request.Header.Add("Accept", "application/json")
+	resp, err := o.httpClient.Do(request)
+	if err != nil {
+		logger.Errorw("Failed to perform HTTP call", "err", err)
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		logger.Warnw("Failed to decode response", "url", u, "err", err)
+		return resp.StatusCode, err, nil
+	}
+	return resp.StatusCode, nil, nil
+}
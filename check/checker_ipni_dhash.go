@@ -0,0 +1,115 @@
+package check
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipni/lookout/sample"
+	"github.com/multiformats/go-multihash"
+)
+
+var _ Checker = (*IpniDoubleHashedChecker)(nil)
+
+type (
+	// IpniDoubleHashedChecker checks privacy preserving lookups, where the double hashed multihash
+	// is looked up via /encrypted/multihash/{hash(mh)}. The returned encrypted value keys are then
+	// decrypted using the original multihash, and the provider metadata resolved for each.
+	IpniDoubleHashedChecker struct {
+		*options
+	}
+)
+
+func NewIpniDoubleHashedChecker(o ...Option) (*IpniDoubleHashedChecker, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	opts.lookupPaths = []LookupPath{LookupPathEncryptedMultihash}
+	return &IpniDoubleHashedChecker{
+		options: opts,
+	}, nil
+}
+
+func (c *IpniDoubleHashedChecker) Check(ctx context.Context, set *sample.Set) *Results {
+	return c.checkInParallel(ctx, set, func(ctx context.Context, mh cid.Cid, lp LookupPath) *Result {
+		result := &Result{
+			Multihash:  mh.Hash(),
+			Timeout:    c.checkTimeout,
+			LookupPath: lp,
+		}
+		smh, err := secondMultihash(result.Multihash)
+		if err != nil {
+			logger.Errorw("Failed to double hash multihash", "err", err)
+			result.Err = err
+			return result
+		}
+		start := time.Now()
+		cctx, cancel := context.WithTimeout(ctx, c.checkTimeout)
+		defer cancel()
+
+		var efr encryptedFindResponse
+		result.StatusCode, result.DecodeErr, result.Err = c.getJSON(cctx, c.ipniEndpoint.JoinPath("encrypted", "multihash", smh.B58String()), &efr)
+		if result.Err != nil || result.DecodeErr != nil || result.StatusCode != http.StatusOK {
+			result.Elapsed = time.Since(start)
+			return result
+		}
+
+		var prs []providerResult
+		for _, emhr := range efr.EncryptedMultihashResults {
+			if !bytes.Equal(emhr.Multihash, smh) {
+				continue
+			}
+			for _, evk := range emhr.EncryptedValueKeys {
+				pr, status, decodeErr, err := c.resolveEncryptedValueKey(cctx, evk, result.Multihash)
+				switch {
+				case err != nil:
+					logger.Warnw("Failed to look up metadata of encrypted value key", "err", err)
+					result.Err = err
+				case status != http.StatusOK:
+					logger.Warnw("Unsuccessful metadata lookup of encrypted value key", "status", status)
+					result.StatusCode = status
+				case decodeErr != nil:
+					logger.Warnw("Failed to decode encrypted value key", "err", decodeErr)
+					result.DecodeErr = decodeErr
+				default:
+					prs = append(prs, *pr)
+				}
+			}
+		}
+		result.setProviders(prs)
+		result.Elapsed = time.Since(start)
+		return result
+	})
+}
+
+// resolveEncryptedValueKey decrypts the given encrypted value key and resolves its provider
+// metadata. Like getJSON, it returns the status code of the metadata lookup, an error if the value
+// key or the metadata could not be decrypted or decoded, and an error if the lookup failed.
+// Decryption failures are reported with status code 200 OK, since no lookup is attempted.
+func (c *IpniDoubleHashedChecker) resolveEncryptedValueKey(ctx context.Context, evk []byte, mh multihash.Multihash) (*providerResult, int, error, error) {
+	vk, err := decryptValueKey(evk, mh)
+	if err != nil {
+		return nil, http.StatusOK, err, nil
+	}
+	pid, contextID, err := splitValueKey(vk)
+	if err != nil {
+		return nil, http.StatusOK, err, nil
+	}
+	var emr encryptedMetadataResponse
+	status, decodeErr, err := c.getJSON(ctx, c.ipniEndpoint.JoinPath("metadata", metadataKey(vk)), &emr)
+	if err != nil || decodeErr != nil || status != http.StatusOK {
+		return nil, status, decodeErr, err
+	}
+	md, err := decryptMetadata(emr.EncryptedMetadata, vk)
+	if err != nil {
+		return nil, status, err, nil
+	}
+	return &providerResult{
+		ContextID: contextID,
+		Metadata:  md,
+		Provider:  &addrInfo{ID: pid},
+	}, status, nil, nil
+}
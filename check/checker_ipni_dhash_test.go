@@ -0,0 +1,95 @@
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipni/lookout/sample"
+	"github.com/mr-tron/base58"
+)
+
+func TestIpniDoubleHashedChecker_MetadataFailure(t *testing.T) {
+	mh := testMultihash(t)
+	smh, err := secondMultihash(mh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := base58.Decode(testDhashPeerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evk := testEncryptValueKey(t, pid, []byte("fish"), mh)
+
+	tests := []struct {
+		name     string
+		metadata http.HandlerFunc
+		timeout  time.Duration
+		want     FailureReason
+	}{
+		{
+			name: "server error",
+			metadata: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			want: FailureReasonServerError,
+		},
+		{
+			name: "not found",
+			metadata: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			want: FailureReasonNotFound,
+		},
+		{
+			name: "timeout",
+			metadata: func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			},
+			timeout: 100 * time.Millisecond,
+			want:    FailureReasonTimeout,
+		},
+		{
+			name: "undecryptable metadata",
+			metadata: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(`{"EncryptedMetadata":"AAAA"}`))
+			},
+			want: FailureReasonDecodeError,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasPrefix(r.URL.Path, "/metadata/") {
+					test.metadata(w, r)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(&encryptedFindResponse{
+					EncryptedMultihashResults: []encryptedMultihashResult{{Multihash: smh, EncryptedValueKeys: [][]byte{evk}}},
+				})
+			}))
+			defer endpoint.Close()
+
+			opts := []Option{WithIpniEndpoint(endpoint.URL)}
+			if test.timeout != 0 {
+				opts = append(opts, WithCheckTimeout(test.timeout))
+			}
+			c, err := NewIpniDoubleHashedChecker(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results := c.Check(context.Background(), &sample.Set{Cids: []cid.Cid{cid.NewCidV1(cid.Raw, mh)}})
+			if len(results.Results) != 1 {
+				t.Fatalf("expected 1 result; got %d", len(results.Results))
+			}
+			if got := results.Results[0].FailureReason(); got != test.want {
+				t.Fatalf("expected failure reason %s; got %s", test.want, got)
+			}
+		})
+	}
+}
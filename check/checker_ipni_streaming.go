@@ -0,0 +1,99 @@
+package check
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipni/lookout/sample"
+)
+
+var _ Checker = (*IpniStreamingChecker)(nil)
+
+// maxNdjsonLineSize is the maximum size of a single provider record in a streaming response.
+const maxNdjsonLineSize = 1 << 20
+
+type (
+	// IpniStreamingChecker checks lookups via the IPNI streaming response format, i.e.
+	// application/x-ndjson, where each line is a single provider record. Alongside the time to the
+	// end of stream it records the time it took for the first provider record to arrive.
+	IpniStreamingChecker struct {
+		*options
+	}
+)
+
+func NewIpniStreamingChecker(o ...Option) (*IpniStreamingChecker, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	return &IpniStreamingChecker{
+		options: opts,
+	}, nil
+}
+
+func (c *IpniStreamingChecker) Check(ctx context.Context, set *sample.Set) *Results {
+	return c.checkInParallel(ctx, set, func(ctx context.Context, mh cid.Cid, lp LookupPath) *Result {
+		result := &Result{
+			Multihash:  mh.Hash(),
+			Timeout:    c.checkTimeout,
+			LookupPath: lp,
+			Streaming:  true,
+		}
+		start := time.Now()
+		cctx, cancel := context.WithTimeout(ctx, c.checkTimeout)
+		defer cancel()
+		request, err := http.NewRequestWithContext(cctx, http.MethodGet, c.lookupURL(mh, lp).String(), nil)
+		if err != nil {
+			logger.Errorw("Failed to instantiate HTTP request", "err", err)
+			result.Err = err
+			return result
+		}
+		request.Header.Add("Accept", "application/x-ndjson")
+		resp, err := c.httpClient.Do(request)
+		if err != nil {
+			logger.Errorw("Failed to perform HTTP call", "err", err)
+			result.Err = err
+			return result
+		}
+		defer resp.Body.Close()
+		result.StatusCode = resp.StatusCode
+		if resp.StatusCode != http.StatusOK {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			result.Elapsed = time.Since(start)
+			return result
+		}
+
+		var prs []providerResult
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 4096), maxNdjsonLineSize)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var pr providerResult
+			if err := json.Unmarshal(line, &pr); err != nil {
+				logger.Warnw("Failed to decode streaming provider record", "err", err)
+				result.DecodeErr = err
+				continue
+			}
+			if result.TimeToFirstProvider == 0 {
+				result.TimeToFirstProvider = time.Since(start)
+			}
+			prs = append(prs, pr)
+		}
+		result.setProviders(prs)
+		if err := scanner.Err(); err != nil {
+			logger.Errorw("Failed to read streaming response", "err", err)
+			result.Err = err
+		}
+		result.Elapsed = time.Since(start)
+		return result
+	})
+}
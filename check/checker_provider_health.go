@@ -0,0 +1,219 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipni/lookout/internal/maddr"
+	"github.com/ipni/lookout/perform"
+)
+
+var (
+	_ EndpointChecker = (*ProviderHealthChecker)(nil)
+	_ EndpointResults = (*ProviderHealthResults)(nil)
+)
+
+type (
+	// ProviderHealthChecker reports the ingestion health of providers as seen by an IPNI endpoint.
+	// It queries /providers/{id} for each configured provider peer ID, or /providers when none is
+	// configured, and probes the publisher of every provider for reachability:
+	//  - HTTP publishers are probed by a GET request to their advertisement chain head, and
+	//  - libp2p publishers are probed by dialling their TCP addresses.
+	ProviderHealthChecker struct {
+		*options
+	}
+	ProviderHealthResults struct {
+		CheckerName string
+		Providers   []*ProviderHealth
+		// Err is the error that occurred while listing all providers, if any.
+		Err error
+	}
+	// ProviderHealth is the health of a single provider as seen by an IPNI endpoint.
+	ProviderHealth struct {
+		ID string
+		// StatusCode is the HTTP status code of the provider info response. It is only set when
+		// providers are queried individually.
+		StatusCode int
+		// Err is the error that occurred while querying the provider info, if any.
+		Err error
+		// LastAdvertisement is the last advertisement processed by the endpoint.
+		LastAdvertisement cid.Cid
+		// LastAdvertisementTime is the time at which the last advertisement was processed.
+		LastAdvertisementTime time.Time
+		// Lag is the number of advertisements the endpoint is behind the publisher head, as
+		// reported by the endpoint.
+		Lag int
+		// Frozen is whether the endpoint has frozen ingestion of the provider.
+		Frozen bool
+		// LastError is the last ingestion error the endpoint recorded for the provider, if any.
+		LastError     string
+		LastErrorTime time.Time
+		// Publisher is the peer ID of the provider publisher, if known.
+		Publisher          string
+		PublisherAddrs     []string
+		PublisherReachable bool
+		PublisherErr       error
+	}
+)
+
+func NewProviderHealthChecker(o ...Option) (*ProviderHealthChecker, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	return &ProviderHealthChecker{
+		options: opts,
+	}, nil
+}
+
+// Checker returns the name of the checker that produced the results.
+func (r *ProviderHealthResults) Checker() string {
+	return r.CheckerName
+}
+
+// HasError checks whether the endpoint recorded an ingestion error for the provider.
+func (h *ProviderHealth) HasError() bool {
+	return h.LastError != ""
+}
+
+func (c *ProviderHealthChecker) CheckEndpoint(ctx context.Context) EndpointResults {
+	results := &ProviderHealthResults{CheckerName: c.name}
+	var healths []*ProviderHealth
+	if len(c.providerIDs) == 0 {
+		healths, results.Err = c.listProviders(ctx)
+	} else {
+		healths = c.gather(ctx, perform.InParallel(ctx, c.parallelism, c.providerIDs, c.getProvider))
+	}
+	results.Providers = c.gather(ctx, perform.InParallel(ctx, c.parallelism, healths, func(ctx context.Context, h *ProviderHealth) *ProviderHealth {
+		return c.probePublisher(ctx, h)
+	}))
+	return results
+}
+
+// gather collects the healths from the given channel until it is closed or the context is done.
+func (c *ProviderHealthChecker) gather(ctx context.Context, hch <-chan *ProviderHealth) []*ProviderHealth {
+	var healths []*ProviderHealth
+	for {
+		select {
+		case <-ctx.Done():
+			return healths
+		case h, ok := <-hch:
+			if !ok {
+				return healths
+			}
+			healths = append(healths, h)
+		}
+	}
+}
+
+func (c *ProviderHealthChecker) listProviders(ctx context.Context) ([]*ProviderHealth, error) {
+	cctx, cancel := context.WithTimeout(ctx, c.checkTimeout)
+	defer cancel()
+	var pis []providerInfo
+	status, decodeErr, err := c.getJSON(cctx, c.ipniEndpoint.JoinPath("providers"), &pis)
+	switch {
+	case err != nil:
+		return nil, err
+	case decodeErr != nil:
+		return nil, decodeErr
+	case status != http.StatusOK:
+		return nil, fmt.Errorf("unsuccessful providers response: %d", status)
+	}
+	healths := make([]*ProviderHealth, 0, len(pis))
+	for _, pi := range pis {
+		h := &ProviderHealth{ID: pi.AddrInfo.ID}
+		h.setInfo(&pi)
+		healths = append(healths, h)
+	}
+	return healths, nil
+}
+
+func (c *ProviderHealthChecker) getProvider(ctx context.Context, id string) *ProviderHealth {
+	cctx, cancel := context.WithTimeout(ctx, c.checkTimeout)
+	defer cancel()
+	h := &ProviderHealth{ID: id}
+	var pi providerInfo
+	var decodeErr error
+	h.StatusCode, decodeErr, h.Err = c.getJSON(cctx, c.ipniEndpoint.JoinPath("providers", id), &pi)
+	switch {
+	case h.Err != nil:
+	case decodeErr != nil:
+		h.Err = decodeErr
+	case h.StatusCode != http.StatusOK:
+		h.Err = fmt.Errorf("unsuccessful provider info response: %d", h.StatusCode)
+	default:
+		h.setInfo(&pi)
+	}
+	return h
+}
+
+func (h *ProviderHealth) setInfo(pi *providerInfo) {
+	h.LastAdvertisement = pi.LastAdvertisement
+	h.LastAdvertisementTime = pi.LastAdvertisementTime
+	h.Lag = pi.Lag
+	h.Frozen = pi.FrozenAt.Defined() || !pi.FrozenAtTime.IsZero()
+	h.LastError = pi.LastError
+	h.LastErrorTime = pi.LastErrorTime
+	if pi.Publisher != nil {
+		h.Publisher = pi.Publisher.ID
+		h.PublisherAddrs = pi.Publisher.Addrs
+	}
+}
+
+// probePublisher checks whether any of the publisher addresses of the given provider are
+// reachable, within the configured retrieval timeout.
+func (c *ProviderHealthChecker) probePublisher(ctx context.Context, h *ProviderHealth) *ProviderHealth {
+	if h.Err != nil {
+		return h
+	}
+	cctx, cancel := context.WithTimeout(ctx, c.retrievalTimeout)
+	defer cancel()
+	h.PublisherErr = errNoDialableAddrs
+	var dialer net.Dialer
+	for _, addr := range h.PublisherAddrs {
+		ma, perr := maddr.Parse(addr)
+		if perr != nil {
+			continue
+		}
+		if ma.IsHTTP() {
+			u, _ := ma.URL()
+			h.PublisherErr = c.probeHttpPublisher(cctx, u.JoinPath("ipni", "v1", "ad", "head").String())
+			if h.PublisherErr != nil {
+				h.PublisherErr = c.probeHttpPublisher(cctx, u.JoinPath("head").String())
+			}
+		} else {
+			var conn net.Conn
+			if conn, h.PublisherErr = dialer.DialContext(cctx, "tcp", ma.HostPort()); h.PublisherErr == nil {
+				_ = conn.Close()
+			}
+		}
+		if h.PublisherErr == nil {
+			break
+		}
+	}
+	h.PublisherReachable = h.PublisherErr == nil
+	return h
+}
+
+// probeHttpPublisher checks whether the given publisher head URL responds successfully.
+func (c *ProviderHealthChecker) probeHttpPublisher(ctx context.Context, head string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, head, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unsuccessful publisher head response from %s: %d", head, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipni/lookout/internal/maddr"
+	"github.com/ipni/lookout/perform"
+	"github.com/ipni/lookout/sample"
+)
+
+var (
+	_ Checker = (*RetrievabilityChecker)(nil)
+
+	errNoDialableAddrs = errors.New("no dialable addresses")
+)
+
+type (
+	// RetrievabilityChecker looks up each CID via the IPNI non-streaming find API and probes every
+	// returned provider for retrievability, using the transfer protocols advertised in provider
+	// metadata:
+	//  - HTTP providers are probed by a trustless gateway HEAD request to /ipfs/{cid}, and
+	//  - bitswap and graphsync providers are probed by dialling their TCP addresses.
+	//
+	// Other transfer protocols are not probed. At most parallelism probes run concurrently per
+	// check, across all lookups.
+	//
+	// Note that dialling only verifies that the provider is reachable; it does not verify that
+	// the provider actually serves the content over bitswap or graphsync.
+	RetrievabilityChecker struct {
+		*options
+	}
+	retrievalTarget struct {
+		provider *addrInfo
+		protocol string
+	}
+	retrievalOutcome struct {
+		provider  string
+		retrieval *Retrieval
+	}
+)
+
+func NewRetrievabilityChecker(o ...Option) (*RetrievabilityChecker, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	return &RetrievabilityChecker{
+		options: opts,
+	}, nil
+}
+
+func (c *RetrievabilityChecker) Check(ctx context.Context, set *sample.Set) *Results {
+	// Bound the number of concurrent probes across all lookups, since every lookup may return
+	// many providers.
+	probeSlots := make(chan struct{}, c.parallelism)
+	return c.checkInParallel(ctx, set, func(ctx context.Context, target cid.Cid, lp LookupPath) *Result {
+		result, prs := c.find(ctx, target, lp)
+		if len(prs) == 0 {
+			return result
+		}
+		var targets []retrievalTarget
+		for _, pr := range prs {
+			if pr.Provider == nil || pr.Provider.ID == "" {
+				continue
+			}
+			for _, protocol := range metadataProtocols(pr.Metadata) {
+				if isProbeable(protocol) {
+					targets = append(targets, retrievalTarget{provider: pr.Provider, protocol: protocol})
+				}
+			}
+		}
+		result.Retrievals = make(map[string][]*Retrieval)
+		retrievals := perform.InParallel(ctx, c.parallelism, targets, func(ctx context.Context, t retrievalTarget) *retrievalOutcome {
+			select {
+			case <-ctx.Done():
+				return &retrievalOutcome{provider: t.provider.ID, retrieval: &Retrieval{Protocol: t.protocol, Err: ctx.Err()}}
+			case probeSlots <- struct{}{}:
+			}
+			defer func() { <-probeSlots }()
+			return &retrievalOutcome{provider: t.provider.ID, retrieval: c.probe(ctx, target, t.provider, t.protocol)}
+		})
+		for outcome := range retrievals {
+			result.Retrievals[outcome.provider] = append(result.Retrievals[outcome.provider], outcome.retrieval)
+		}
+		return result
+	})
+}
+
+// isProbeable checks whether providers can be probed for retrievability over the given transfer
+// protocol.
+func isProbeable(protocol string) bool {
+	switch protocol {
+	case ProtocolHttp, ProtocolBitswap, ProtocolGraphsync:
+		return true
+	default:
+		return false
+	}
+}
+
+// probe checks whether the given provider is retrievable via the given transfer protocol.
+func (c *RetrievabilityChecker) probe(ctx context.Context, target cid.Cid, provider *addrInfo, protocol string) *Retrieval {
+	retrieval := &Retrieval{Protocol: protocol}
+	cctx, cancel := context.WithTimeout(ctx, c.retrievalTimeout)
+	defer cancel()
+	start := time.Now()
+	switch protocol {
+	case ProtocolHttp:
+		retrieval.Err = c.probeHttp(cctx, target, provider)
+	case ProtocolBitswap, ProtocolGraphsync:
+		retrieval.Err = c.probeDial(cctx, provider)
+	default:
+		retrieval.Err = fmt.Errorf("unsupported transfer protocol: %s", protocol)
+	}
+	retrieval.Elapsed = time.Since(start)
+	retrieval.Retrievable = retrieval.Err == nil
+	return retrieval
+}
+
+// probeHttp checks whether any of the provider HTTP addresses serves the given CID as a trustless
+// gateway.
+func (c *RetrievabilityChecker) probeHttp(ctx context.Context, target cid.Cid, provider *addrInfo) error {
+	err := errNoDialableAddrs
+	for _, addr := range provider.Addrs {
+		ma, perr := maddr.Parse(addr)
+		if perr != nil || !ma.IsHTTP() {
+			continue
+		}
+		u, _ := ma.URL()
+		request, rerr := http.NewRequestWithContext(ctx, http.MethodHead, u.JoinPath("ipfs", target.String()).String(), nil)
+		if rerr != nil {
+			return rerr
+		}
+		request.Header.Add("Accept", "application/vnd.ipld.raw")
+		resp, derr := c.httpClient.Do(request)
+		if derr != nil {
+			err = derr
+			continue
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return nil
+		}
+		err = fmt.Errorf("unsuccessful trustless gateway response from %s: %d", u, resp.StatusCode)
+	}
+	return err
+}
+
+// probeDial checks whether any of the provider TCP addresses can be dialled.
+func (c *RetrievabilityChecker) probeDial(ctx context.Context, provider *addrInfo) error {
+	err := errNoDialableAddrs
+	var dialer net.Dialer
+	for _, addr := range provider.Addrs {
+		ma, perr := maddr.Parse(addr)
+		if perr != nil || ma.IsHTTP() {
+			continue
+		}
+		conn, derr := dialer.DialContext(ctx, "tcp", ma.HostPort())
+		if derr != nil {
+			err = derr
+			continue
+		}
+		_ = conn.Close()
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package check
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/mr-tron/base58"
+	"github.com/multiformats/go-multihash"
+)
+
+// The functions below implement the IPNI reader privacy double hashing scheme.
+// See: https://github.com/ipni/specs/blob/main/IPNI_HASH.md
+
+const dhashNonceLen = 12
+
+var (
+	dhashSecondHashPrefix = []byte("CR_DOUBLEHASH\x00")
+	dhashDeriveKeyPrefix  = []byte("CR_ENCRYPTIONKEY\x00")
+)
+
+// secondMultihash returns the double hashed multihash of the given multihash, used as the lookup
+// key for privacy preserving lookups.
+func secondMultihash(mh multihash.Multihash) (multihash.Multihash, error) {
+	digest := sha256.Sum256(append(append([]byte{}, dhashSecondHashPrefix...), mh...))
+	return multihash.Encode(digest[:], multihash.DBL_SHA2_256)
+}
+
+// decryptValueKey decrypts an encrypted value key using the original multihash.
+func decryptValueKey(encValueKey []byte, mh multihash.Multihash) ([]byte, error) {
+	return dhashDecrypt(encValueKey, dhashDeriveKey(mh))
+}
+
+// decryptMetadata decrypts encrypted provider metadata using the decrypted value key.
+func decryptMetadata(encMetadata []byte, valueKey []byte) ([]byte, error) {
+	return dhashDecrypt(encMetadata, dhashDeriveKey(valueKey))
+}
+
+// splitValueKey splits a decrypted value key into its provider peer ID, encoded as base58 string,
+// and context ID. Both are prefixed by their uvarint encoded length.
+func splitValueKey(valueKey []byte) (string, []byte, error) {
+	pid, rest, err := readLengthPrefixed(valueKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid value key peer ID: %w", err)
+	}
+	contextID, rest, err := readLengthPrefixed(rest)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid value key context ID: %w", err)
+	}
+	if len(rest) != 0 {
+		return "", nil, fmt.Errorf("value key has %d trailing bytes", len(rest))
+	}
+	return base58.Encode(pid), contextID, nil
+}
+
+// readLengthPrefixed reads a uvarint length prefixed byte slice, and returns it along with the
+// remaining bytes.
+func readLengthPrefixed(b []byte) ([]byte, []byte, error) {
+	l, n := binary.Uvarint(b)
+	if n <= 0 {
+		return nil, nil, errors.New("invalid length prefix")
+	}
+	if uint64(len(b)-n) < l {
+		return nil, nil, fmt.Errorf("too short: expected length %d", l)
+	}
+	end := n + int(l)
+	return b[n:end], b[end:], nil
+}
+
+// metadataKey returns the key at which the encrypted metadata for the given value key is looked up.
+func metadataKey(valueKey []byte) string {
+	digest := sha256.Sum256(valueKey)
+	return base58.Encode(digest[:])
+}
+
+func dhashDeriveKey(v []byte) []byte {
+	key := sha256.Sum256(append(append([]byte{}, dhashDeriveKeyPrefix...), v...))
+	return key[:]
+}
+
+func dhashDecrypt(payload, key []byte) ([]byte, error) {
+	if len(payload) < dhashNonceLen {
+		return nil, errors.New("encrypted payload too short")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, payload[:dhashNonceLen], payload[dhashNonceLen:], nil)
+}
@@ -0,0 +1,132 @@
+package check
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/mr-tron/base58"
+	"github.com/multiformats/go-multihash"
+)
+
+// The vectors below are computed independently of this package according to go-libipni dhash:
+//   - second multihash: DBL_SHA2_256 multihash of SHA256("CR_DOUBLEHASH\x00" || mh),
+//   - encryption key:   SHA256("CR_ENCRYPTIONKEY\x00" || mh), and
+//   - nonce:            SHA256("CR_NONCE\x00" || mh)[:12],
+//
+// where mh is the SHA2_256 multihash of "lookout". The peer ID is the Ed25519 test vector from the
+// libp2p peer ID spec.
+const (
+	testDhashMultihash       = "QmZuEna62pLjTTkHdP3Xddv7Uuspy8H6kxRMGHRkJSLVYf"
+	testDhashSecondMultihash = "2wvrc9ooccYVSnp3RC2EbwxcH44QiABfrs3FZ7hqiu62tPr"
+	testDhashKey             = "cbd4302426ab3d8687f4bf389322d546b9461dbd07593bec5eb8acec2e635733"
+	testDhashNonce           = "b3f22a2acbf46e8ff7111c77"
+	testDhashPeerID          = "12D3KooWBtg3aaRMjxwedh83aGiUkwSxDwUZkzuJcfaqUmo7R3pq"
+)
+
+func TestSecondMultihash(t *testing.T) {
+	mh := testMultihash(t)
+	got, err := secondMultihash(mh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.B58String() != testDhashSecondMultihash {
+		t.Fatalf("expected second multihash %s; got %s", testDhashSecondMultihash, got.B58String())
+	}
+}
+
+func TestDhashDeriveKey(t *testing.T) {
+	got := hex.EncodeToString(dhashDeriveKey(testMultihash(t)))
+	if got != testDhashKey {
+		t.Fatalf("expected key %s; got %s", testDhashKey, got)
+	}
+}
+
+func TestDecryptValueKey(t *testing.T) {
+	mh := testMultihash(t)
+	pid, err := base58.Decode(testDhashPeerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contextID := []byte("fish")
+	evk := testEncryptValueKey(t, pid, contextID, mh)
+	if got := hex.EncodeToString(evk[:dhashNonceLen]); got != testDhashNonce {
+		t.Fatalf("expected nonce %s; got %s", testDhashNonce, got)
+	}
+
+	vk, err := decryptValueKey(evk, mh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotPid, gotContextID, err := splitValueKey(vk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPid != testDhashPeerID {
+		t.Fatalf("expected peer ID %s; got %s", testDhashPeerID, gotPid)
+	}
+	if !bytes.Equal(gotContextID, contextID) {
+		t.Fatalf("expected context ID %x; got %x", contextID, gotContextID)
+	}
+
+	// Decrypting with any other multihash must fail.
+	other, err := multihash.Sum([]byte("fish"), multihash.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decryptValueKey(evk, other); err == nil {
+		t.Fatal("expected decryption with wrong multihash to fail")
+	}
+}
+
+func TestSplitValueKey_Invalid(t *testing.T) {
+	for name, vk := range map[string][]byte{
+		"empty":               {},
+		"short peer ID":       {0x05, 0x01, 0x02},
+		"missing context ID":  {0x01, 0x01},
+		"short context ID":    {0x01, 0x01, 0x03, 0x01},
+		"trailing bytes":      {0x01, 0x01, 0x01, 0x02, 0x03},
+		"invalid length data": {0xff},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := splitValueKey(vk); err == nil {
+				t.Fatalf("expected error splitting value key %x", vk)
+			}
+		})
+	}
+}
+
+func testMultihash(t *testing.T) multihash.Multihash {
+	t.Helper()
+	mh, err := multihash.FromB58String(testDhashMultihash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mh
+}
+
+// testEncryptValueKey encrypts the value key of the given peer ID and context ID the same way as
+// go-libipni dhash.EncryptValueKey does.
+func testEncryptValueKey(t *testing.T, pid, contextID []byte, mh multihash.Multihash) []byte {
+	t.Helper()
+	var vk []byte
+	vk = binary.AppendUvarint(vk, uint64(len(pid)))
+	vk = append(vk, pid...)
+	vk = binary.AppendUvarint(vk, uint64(len(contextID)))
+	vk = append(vk, contextID...)
+
+	nonce := sha256.Sum256(append([]byte("CR_NONCE\x00"), mh...))
+	block, err := aes.NewCipher(dhashDeriveKey(mh))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gcm.Seal(append([]byte{}, nonce[:dhashNonceLen]...), nonce[:dhashNonceLen], vk, nil)
+}
@@ -0,0 +1,108 @@
+package check
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+)
+
+const (
+	// FailureReasonNone signals that the check succeeded.
+	FailureReasonNone FailureReason = "none"
+	// FailureReasonDns signals that the endpoint host name could not be resolved.
+	FailureReasonDns FailureReason = "dns"
+	// FailureReasonConnectRefused signals that the endpoint refused the connection.
+	FailureReasonConnectRefused FailureReason = "connect_refused"
+	// FailureReasonTls signals that the TLS handshake with the endpoint failed.
+	FailureReasonTls FailureReason = "tls"
+	// FailureReasonTimeout signals that the check timed out.
+	FailureReasonTimeout FailureReason = "timeout"
+	// FailureReasonCancelled signals that the check was cancelled, e.g. due to shutdown.
+	FailureReasonCancelled FailureReason = "cancelled"
+	// FailureReasonNotFound signals that the endpoint responded with 404 Not Found, i.e. that the
+	// content is missing from the index.
+	FailureReasonNotFound FailureReason = "not_found"
+	// FailureReasonRateLimited signals that the endpoint responded with 429 Too Many Requests.
+	FailureReasonRateLimited FailureReason = "rate_limited"
+	// FailureReasonServerError signals that the endpoint responded with a 5xx status code.
+	FailureReasonServerError FailureReason = "server_error"
+	// FailureReasonUnexpectedStatus signals that the endpoint responded with any other
+	// unsuccessful status code.
+	FailureReasonUnexpectedStatus FailureReason = "unexpected_status"
+	// FailureReasonDecodeError signals that the response body could not be decoded.
+	FailureReasonDecodeError FailureReason = "decode_error"
+	// FailureReasonNoProviders signals that the endpoint responded successfully but with no
+	// provider records for the multihash.
+	FailureReasonNoProviders FailureReason = "no_providers"
+	// FailureReasonOther signals an error that fits none of the other reasons.
+	FailureReasonOther FailureReason = "other"
+)
+
+// FailureReason classifies why a check did not succeed.
+type FailureReason string
+
+// FailureReason classifies why the check did not succeed, or returns FailureReasonNone if it did.
+// Transport errors take precedence over unsuccessful status codes, which in turn take precedence
+// over decode errors.
+func (r *Result) FailureReason() FailureReason {
+	if r.Err != nil {
+		if reason := classifyErr(r.Err); reason != FailureReasonOther {
+			return reason
+		}
+	}
+	switch {
+	case r.StatusCode == http.StatusNotFound:
+		return FailureReasonNotFound
+	case r.StatusCode == http.StatusTooManyRequests:
+		return FailureReasonRateLimited
+	case r.StatusCode >= 500:
+		return FailureReasonServerError
+	case r.DecodeErr != nil:
+		return FailureReasonDecodeError
+	case r.Err != nil:
+		return FailureReasonOther
+	case r.StatusCode != http.StatusOK:
+		return FailureReasonUnexpectedStatus
+	case r.ProviderCount == 0:
+		return FailureReasonNoProviders
+	default:
+		return FailureReasonNone
+	}
+}
+
+// classifyErr classifies the given transport error, or returns FailureReasonOther if it is not
+// recognised.
+func classifyErr(err error) FailureReason {
+	var dnsErr *net.DNSError
+	var recordHeaderErr tls.RecordHeaderError
+	var certVerificationErr *tls.CertificateVerificationError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var certInvalidErr x509.CertificateInvalidError
+	var hostnameErr x509.HostnameError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return FailureReasonCancelled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return FailureReasonTimeout
+	case errors.As(err, &dnsErr):
+		return FailureReasonDns
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return FailureReasonConnectRefused
+	case errors.As(err, &recordHeaderErr),
+		errors.As(err, &certVerificationErr),
+		errors.As(err, &unknownAuthorityErr),
+		errors.As(err, &certInvalidErr),
+		errors.As(err, &hostnameErr):
+		return FailureReasonTls
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return FailureReasonTimeout
+	default:
+		return FailureReasonOther
+	}
+}
@@ -0,0 +1,78 @@
+package check
+
+import "encoding/json"
+
+type (
+	resultJson struct {
+		Multihash           string                      `json:"multihash"`
+		LookupPath          LookupPath                  `json:"lookupPath,omitempty"`
+		StatusCode          int                         `json:"status"`
+		Err                 string                      `json:"err,omitempty"`
+		DecodeErr           string                      `json:"decodeErr,omitempty"`
+		Timeout             string                      `json:"timeout"`
+		Elapsed             string                      `json:"elapsed"`
+		Streaming           bool                        `json:"streaming"`
+		TimeToFirstProvider string                      `json:"timeToFirstProvider,omitempty"`
+		Succeeded           bool                        `json:"succeeded"`
+		FailureReason       FailureReason               `json:"failureReason,omitempty"`
+		ProviderCount       int                         `json:"providerCount"`
+		PeerIDs             []string                    `json:"peerIds,omitempty"`
+		ProvidersByProtocol map[string]int              `json:"providersByProtocol,omitempty"`
+		Retrievals          map[string][]*retrievalJson `json:"retrievals,omitempty"`
+		Comparisons         []*Comparison               `json:"comparisons,omitempty"`
+	}
+	retrievalJson struct {
+		Protocol    string `json:"protocol"`
+		Retrievable bool   `json:"retrievable"`
+		Err         string `json:"err,omitempty"`
+		Elapsed     string `json:"elapsed"`
+	}
+)
+
+// MarshalJSON marshals the result as JSON, representing errors and durations as strings.
+func (r *Result) MarshalJSON() ([]byte, error) {
+	rj := resultJson{
+		LookupPath:          r.LookupPath,
+		StatusCode:          r.StatusCode,
+		Err:                 errString(r.Err),
+		DecodeErr:           errString(r.DecodeErr),
+		Timeout:             r.Timeout.String(),
+		Elapsed:             r.Elapsed.String(),
+		Streaming:           r.Streaming,
+		Succeeded:           r.Succeeded(),
+		ProviderCount:       r.ProviderCount,
+		PeerIDs:             r.PeerIDs,
+		ProvidersByProtocol: r.ProvidersByProtocol,
+		Comparisons:         r.Comparisons,
+	}
+	if reason := r.FailureReason(); reason != FailureReasonNone {
+		rj.FailureReason = reason
+	}
+	if r.TimeToFirstProvider != 0 {
+		rj.TimeToFirstProvider = r.TimeToFirstProvider.String()
+	}
+	if r.Multihash != nil {
+		rj.Multihash = r.Multihash.B58String()
+	}
+	if len(r.Retrievals) != 0 {
+		rj.Retrievals = make(map[string][]*retrievalJson, len(r.Retrievals))
+		for provider, retrievals := range r.Retrievals {
+			for _, retrieval := range retrievals {
+				rj.Retrievals[provider] = append(rj.Retrievals[provider], &retrievalJson{
+					Protocol:    retrieval.Protocol,
+					Retrievable: retrieval.Retrievable,
+					Err:         errString(retrieval.Err),
+					Elapsed:     retrieval.Elapsed.String(),
+				})
+			}
+		}
+	}
+	return json.Marshal(rj)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
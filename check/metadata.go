@@ -0,0 +1,39 @@
+package check
+
+import "encoding/binary"
+
+// Multicodec codes of the transport protocols that may appear in IPNI provider metadata.
+// See: https://github.com/multiformats/multicodec/blob/master/table.csv
+const (
+	transportBitswapCode             = 0x0900
+	transportGraphsyncFilecoinv1Code = 0x0910
+	transportIpfsGatewayHttpCode     = 0x0920
+)
+
+// metadataProtocols returns the transfer protocols advertised in the given IPNI provider metadata,
+// as one of ProtocolBitswap, ProtocolGraphsync, ProtocolHttp or ProtocolOther.
+//
+// Metadata is a sequence of varint transport codes each followed by an optional transport
+// specific payload. Only bitswap and HTTP payloads are known to be empty, so parsing stops at the
+// first protocol that carries a payload.
+func metadataProtocols(md []byte) []string {
+	var protocols []string
+	for len(md) > 0 {
+		code, n := binary.Uvarint(md)
+		if n <= 0 {
+			break
+		}
+		md = md[n:]
+		switch code {
+		case transportBitswapCode:
+			protocols = append(protocols, ProtocolBitswap)
+		case transportIpfsGatewayHttpCode:
+			protocols = append(protocols, ProtocolHttp)
+		case transportGraphsyncFilecoinv1Code:
+			return append(protocols, ProtocolGraphsync)
+		default:
+			return append(protocols, ProtocolOther)
+		}
+	}
+	return protocols
+}
@@ -0,0 +1,73 @@
+package check
+
+import (
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+// The types below mirror the JSON representation of IPNI find responses.
+// See: https://github.com/ipni/specs/blob/main/IPNI.md
+type (
+	findResponse struct {
+		MultihashResults []multihashResult
+	}
+	multihashResult struct {
+		Multihash       multihash.Multihash
+		ProviderResults []providerResult
+	}
+	providerResult struct {
+		ContextID []byte
+		Metadata  []byte
+		Provider  *addrInfo
+	}
+	addrInfo struct {
+		ID    string
+		Addrs []string
+	}
+)
+
+// The types below mirror the JSON representation of IPNI provider info responses.
+// See: https://github.com/ipni/specs/blob/main/IPNI.md#get-providers
+type providerInfo struct {
+	AddrInfo              addrInfo
+	LastAdvertisement     cid.Cid
+	LastAdvertisementTime time.Time
+	Publisher             *addrInfo
+	FrozenAt              cid.Cid
+	FrozenAtTime          time.Time
+	LastError             string
+	LastErrorTime         time.Time
+	Lag                   int
+}
+
+// The types below mirror the JSON representation of IPNI reader privacy responses.
+type (
+	encryptedFindResponse struct {
+		EncryptedMultihashResults []encryptedMultihashResult
+	}
+	encryptedMultihashResult struct {
+		Multihash          multihash.Multihash
+		EncryptedValueKeys [][]byte
+	}
+	encryptedMetadataResponse struct {
+		EncryptedMetadata []byte
+	}
+)
+
+// The types below mirror the JSON representation of HTTP delegated routing responses.
+// See: https://specs.ipfs.tech/routing/http-routing-v1/
+type (
+	routingProvidersResponse struct {
+		Providers []routingProviderRecord
+	}
+	routingProviderRecord struct {
+		Schema    string
+		ID        string
+		Addrs     []string
+		Protocols []string
+		// Protocol is set by the deprecated bitswap and graphsync-fil schemas instead of Protocols.
+		Protocol string
+	}
+)
@@ -1,6 +1,7 @@
 package check
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -10,20 +11,47 @@ import (
 type (
 	Option  func(*options) error
 	options struct {
-		name          string
-		httpClient    *http.Client
-		checkTimeout  time.Duration
-		ipniEndpoint  *url.URL
-		parallelism   int
-		cascadeLabels []string
+		name              string
+		httpClient        *http.Client
+		checkTimeout      time.Duration
+		ipniEndpoint      *url.URL
+		parallelism       int
+		cascadeLabels     []string
+		lookupPaths       []LookupPath
+		streaming         bool
+		retrievalTimeout  time.Duration
+		comparedEndpoints []namedEndpoint
+		providerIDs       []string
+		countProviders    bool
 	}
+	namedEndpoint struct {
+		name     string
+		endpoint *url.URL
+	}
+	// LookupPath represents the IPNI lookup API path via which a CID is looked up.
+	LookupPath string
+)
+
+const (
+	// LookupPathCid looks up CIDs via /cid/{cid}.
+	LookupPathCid LookupPath = "cid"
+	// LookupPathMultihash looks up the multihash of CIDs via /multihash/{mh}.
+	LookupPathMultihash LookupPath = "multihash"
+	// LookupPathEncryptedMultihash looks up the double hashed multihash of CIDs via
+	// /encrypted/multihash/{hash(mh)}. It is used exclusively by IpniDoubleHashedChecker.
+	LookupPathEncryptedMultihash LookupPath = "encrypted-multihash"
+	// LookupPathRoutingProviders looks up CIDs via HTTP delegated routing API at
+	// /routing/v1/providers/{cid}. It is used exclusively by DelegatedRoutingChecker.
+	LookupPathRoutingProviders LookupPath = "routing-providers"
 )
 
 func newOptions(o ...Option) (*options, error) {
 	opts := options{
-		httpClient:   http.DefaultClient,
-		parallelism:  10,
-		checkTimeout: 30 * time.Second,
+		httpClient:       http.DefaultClient,
+		parallelism:      10,
+		checkTimeout:     30 * time.Second,
+		lookupPaths:      []LookupPath{LookupPathCid},
+		retrievalTimeout: 10 * time.Second,
 	}
 	for _, apply := range o {
 		if err := apply(&opts); err != nil {
@@ -43,6 +71,11 @@ func newOptions(o ...Option) (*options, error) {
 	return &opts, nil
 }
 
+// Name returns the name of the checker.
+func (o *options) Name() string {
+	return o.name
+}
+
 func WithName(name string) Option {
 	return func(o *options) error {
 		o.name = name
@@ -91,3 +124,76 @@ func WithCascadeLabels(l []string) Option {
 		return nil
 	}
 }
+
+// WithLookupPaths sets the lookup API paths via which each CID is checked. When more than one path
+// is specified, every CID is checked once per path and the results are labelled accordingly.
+// Defaults to LookupPathCid.
+func WithLookupPaths(lps ...LookupPath) Option {
+	return func(o *options) error {
+		if len(lps) == 0 {
+			return errors.New("at least one lookup path must be specified")
+		}
+		for _, lp := range lps {
+			switch lp {
+			case LookupPathCid, LookupPathMultihash:
+			default:
+				return fmt.Errorf("unknown lookup path: %s", lp)
+			}
+		}
+		o.lookupPaths = lps
+		return nil
+	}
+}
+
+// WithStreaming sets whether to request streaming responses, i.e. application/x-ndjson, from
+// checkers that support both response variants. Defaults to false.
+func WithStreaming(s bool) Option {
+	return func(o *options) error {
+		o.streaming = s
+		return nil
+	}
+}
+
+// WithRetrievalTimeout sets the timeout for probing each provider for retrievability.
+// Defaults to 10 seconds.
+func WithRetrievalTimeout(t time.Duration) Option {
+	return func(o *options) error {
+		o.retrievalTimeout = t
+		return nil
+	}
+}
+
+// WithComparedEndpoint adds an IPNI endpoint to compare lookup results against, used by
+// ConsistencyChecker only. Endpoints are compared in the order at which they are added.
+func WithComparedEndpoint(name, endpoint string) Option {
+	return func(o *options) error {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = u.Host
+		}
+		o.comparedEndpoints = append(o.comparedEndpoints, namedEndpoint{name: name, endpoint: u})
+		return nil
+	}
+}
+
+// WithCountProviders sets whether to count the providers known to the IPNI endpoint, used by
+// IndexerStatusChecker only. Counting downloads the full /providers listing, and as such is
+// disabled by default.
+func WithCountProviders(c bool) Option {
+	return func(o *options) error {
+		o.countProviders = c
+		return nil
+	}
+}
+
+// WithProviderIDs sets the peer IDs of providers whose health is checked, used by
+// ProviderHealthChecker only. Defaults to all providers known to the IPNI endpoint.
+func WithProviderIDs(ids ...string) Option {
+	return func(o *options) error {
+		o.providerIDs = ids
+		return nil
+	}
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,37 +9,137 @@ import (
 
 	"github.com/ipni/lookout"
 	"github.com/ipni/lookout/check"
+	"github.com/ipni/lookout/probe"
 	"github.com/ipni/lookout/sample"
+	"github.com/ipni/lookout/sink"
 	"gopkg.in/yaml.v2"
 )
 
 type (
-	CheckerType string
-	SamplerType string
-	Config      struct {
+	CheckerType   string
+	SamplerType   string
+	SinkType      string
+	ExtractMode   string
+	ProbeType     string
+	SamplerConfig struct {
+		Type    SamplerType       `yaml:"type"`
+		File    string            `yaml:"file"`
+		Format  string            `yaml:"format"`
+		Cids    []string          `yaml:"cids"`
+		Url     string            `yaml:"url"`
+		Headers map[string]string `yaml:"headers"`
+		Extract struct {
+			Mode    ExtractMode `yaml:"mode"`
+			Path    string      `yaml:"path"`
+			Column  string      `yaml:"column"`
+			Pattern string      `yaml:"pattern"`
+		} `yaml:"extract"`
+		PublisherUrl      string                   `yaml:"publisherUrl"`
+		MaxAds            int                      `yaml:"maxAds"`
+		EntriesPerAd      int                      `yaml:"entriesPerAd"`
+		MaxChunksPerAd    int                      `yaml:"maxChunksPerAd"`
+		AdSelection       sample.AdSelection       `yaml:"adSelection"`
+		IpniEndpoint      string                   `yaml:"ipniEndpoint"`
+		ProviderCount     int                      `yaml:"providerCount"`
+		ProviderWeighting sample.ProviderWeighting `yaml:"providerWeighting"`
+		MaxSize           int                      `yaml:"maxSize"`
+		Subsampling       sample.Subsampling       `yaml:"subsampling"`
+		Seed              *int64                   `yaml:"seed"`
+		CacheTTL          time.Duration            `yaml:"cacheTTL"`
+		Snapshot          string                   `yaml:"snapshot"`
+		DedupeByCid       bool                     `yaml:"dedupeByCid"`
+		PathGateway       string                   `yaml:"pathGateway"`
+		Sources           []struct {
+			Name   string  `yaml:"name"`
+			Weight float64 `yaml:"weight"`
+		} `yaml:"sources"`
+		Standalone *bool `yaml:"standalone"`
+	}
+	Config struct {
 		Checkers map[string]struct {
-			Type          CheckerType   `yaml:"type"`
-			Timeout       time.Duration `yaml:"timeout"`
-			IpniEndpoint  string        `yaml:"ipniEndpoint"`
-			CascadeLabels []string      `yaml:"cascadeLabels"`
-			Parallelism   int           `yaml:"parallelism"`
+			Type             CheckerType   `yaml:"type"`
+			Timeout          time.Duration `yaml:"timeout"`
+			IpniEndpoint     string        `yaml:"ipniEndpoint"`
+			CascadeLabels    []string      `yaml:"cascadeLabels"`
+			Parallelism      int           `yaml:"parallelism"`
+			LookupPath       string        `yaml:"lookupPath"`
+			RetrievalTimeout time.Duration `yaml:"retrievalTimeout"`
+			Providers        []string      `yaml:"providers"`
+			CountProviders   bool          `yaml:"countProviders"`
+			Endpoints        []struct {
+				Name string `yaml:"name"`
+				Url  string `yaml:"url"`
+			} `yaml:"endpoints"`
 		} `yaml:"checkers"`
-		Samplers map[string]struct {
-			Type SamplerType `yaml:"type"`
-		} `yaml:"samplers"`
+		Samplers    map[string]SamplerConfig `yaml:"samplers"`
+		ResultsSink *struct {
+			Type       SinkType      `yaml:"type"`
+			Path       string        `yaml:"path"`
+			MaxAge     time.Duration `yaml:"maxAge"`
+			MaxRecords int           `yaml:"maxRecords"`
+		} `yaml:"resultsSink"`
+		Publisher *struct {
+			ListenAddr    string   `yaml:"listenAddr"`
+			Addrs         []string `yaml:"addrs"`
+			ProviderAddrs []string `yaml:"providerAddrs"`
+			AnnounceUrls  []string `yaml:"announceUrls"`
+			IdentityPath  string   `yaml:"identityPath"`
+			Topic         string   `yaml:"topic"`
+		} `yaml:"publisher"`
+		Probes map[string]struct {
+			Type         ProbeType     `yaml:"type"`
+			Checker      string        `yaml:"checker"`
+			Entries      int           `yaml:"entries"`
+			PollInterval time.Duration `yaml:"pollInterval"`
+			Timeout      time.Duration `yaml:"timeout"`
+		} `yaml:"probes"`
+		ProbeInterval       time.Duration `yaml:"probeInterval"`
 		CheckInterval       time.Duration `yaml:"checkInterval"`
 		CheckersParallelism int           `yaml:"checkersParallelism"`
 		SamplersParallelism int           `yaml:"samplersParallelism"`
 		MetricsListenAddr   string        `yaml:"metricsListenAddr"`
+		MaxCheckCids        int           `yaml:"maxCheckCids"`
+		SnapshotDir         string        `yaml:"snapshotDir"`
+		SnapshotMaxCount    *int          `yaml:"snapshotMaxCount"`
+		SnapshotMaxAge      time.Duration `yaml:"snapshotMaxAge"`
 	}
 )
 
 const (
-	ipniNonStreamingChecker CheckerType = "ipni-non-streaming"
+	ipniNonStreamingChecker          CheckerType = "ipni-non-streaming"
+	ipniStreamingChecker             CheckerType = "ipni-streaming"
+	ipniDoubleHashedChecker          CheckerType = "ipni-double-hashed"
+	delegatedRoutingChecker          CheckerType = "delegated-routing"
+	delegatedRoutingStreamingChecker CheckerType = "delegated-routing-streaming"
+	retrievabilityChecker            CheckerType = "retrievability"
+	consistencyChecker               CheckerType = "consistency"
+	providerHealthChecker            CheckerType = "provider-health"
+	indexerStatusChecker             CheckerType = "ipni-status"
+
+	lookupPathBoth = "both"
 
 	saturnOrchestratorTopCids SamplerType = "saturn-orch-top-cids"
 	awesomeIpfsDatasets       SamplerType = "awesome-ipfs-datasets"
 	internetArchiveTopCids    SamplerType = "internet-archive-top-cids"
+	staticSampler             SamplerType = "static"
+	httpSampler               SamplerType = "http"
+	adChainSampler            SamplerType = "ad-chain"
+	providersSampler          SamplerType = "ipni-providers"
+	replaySampler             SamplerType = "replay"
+	unionSampler              SamplerType = "union"
+	intersectionSampler       SamplerType = "intersection"
+	differenceSampler         SamplerType = "difference"
+	mixSampler                SamplerType = "mix"
+
+	jsonPathExtractMode  ExtractMode = "jsonpath"
+	csvColumnExtractMode ExtractMode = "csv"
+	regexExtractMode     ExtractMode = "regex"
+	linesExtractMode     ExtractMode = "lines"
+
+	boltSink SinkType = "bolt"
+
+	publishFindProbe ProbeType = "publish-find"
+	removalProbe     ProbeType = "removal"
 )
 
 func NewConfig(p string) (*Config, error) {
@@ -56,6 +157,8 @@ func NewConfig(p string) (*Config, error) {
 func (c *Config) ToOptions() ([]lookout.Option, error) {
 	var opts []lookout.Option
 	var checkers []check.Checker
+	var endpointCheckers []check.EndpointChecker
+	checkersByName := make(map[string]check.Checker)
 	for name, cc := range c.Checkers {
 		copts := []check.Option{
 			check.WithName(name),
@@ -70,6 +173,25 @@ func (c *Config) ToOptions() ([]lookout.Option, error) {
 		if cc.IpniEndpoint != "" {
 			copts = append(copts, check.WithIpniEndpoint(cc.IpniEndpoint))
 		}
+		for _, endpoint := range cc.Endpoints {
+			copts = append(copts, check.WithComparedEndpoint(endpoint.Name, endpoint.Url))
+		}
+		if cc.RetrievalTimeout != 0 {
+			copts = append(copts, check.WithRetrievalTimeout(cc.RetrievalTimeout))
+		}
+		if len(cc.Providers) != 0 {
+			copts = append(copts, check.WithProviderIDs(cc.Providers...))
+		}
+		if cc.CountProviders {
+			copts = append(copts, check.WithCountProviders(true))
+		}
+		switch cc.LookupPath {
+		case "":
+		case lookupPathBoth:
+			copts = append(copts, check.WithLookupPaths(check.LookupPathCid, check.LookupPathMultihash))
+		default:
+			copts = append(copts, check.WithLookupPaths(check.LookupPath(cc.LookupPath)))
+		}
 
 		switch cc.Type {
 		case ipniNonStreamingChecker:
@@ -78,39 +200,159 @@ func (c *Config) ToOptions() ([]lookout.Option, error) {
 				return nil, err
 			}
 			checkers = append(checkers, checker)
+		case ipniStreamingChecker:
+			checker, err := check.NewIpniStreamingChecker(copts...)
+			if err != nil {
+				return nil, err
+			}
+			checkers = append(checkers, checker)
+		case ipniDoubleHashedChecker:
+			checker, err := check.NewIpniDoubleHashedChecker(copts...)
+			if err != nil {
+				return nil, err
+			}
+			checkers = append(checkers, checker)
+		case delegatedRoutingChecker:
+			checker, err := check.NewDelegatedRoutingChecker(copts...)
+			if err != nil {
+				return nil, err
+			}
+			checkers = append(checkers, checker)
+		case delegatedRoutingStreamingChecker:
+			checker, err := check.NewDelegatedRoutingChecker(append(copts, check.WithStreaming(true))...)
+			if err != nil {
+				return nil, err
+			}
+			checkers = append(checkers, checker)
+		case retrievabilityChecker:
+			checker, err := check.NewRetrievabilityChecker(copts...)
+			if err != nil {
+				return nil, err
+			}
+			checkers = append(checkers, checker)
+		case consistencyChecker:
+			checker, err := check.NewConsistencyChecker(copts...)
+			if err != nil {
+				return nil, err
+			}
+			checkers = append(checkers, checker)
+		case providerHealthChecker:
+			checker, err := check.NewProviderHealthChecker(copts...)
+			if err != nil {
+				return nil, err
+			}
+			endpointCheckers = append(endpointCheckers, checker)
+			// Endpoint checkers check no sample sets, and as such cannot be referenced by probes.
+			continue
+		case indexerStatusChecker:
+			checker, err := check.NewIndexerStatusChecker(copts...)
+			if err != nil {
+				return nil, err
+			}
+			endpointCheckers = append(endpointCheckers, checker)
+			continue
 		default:
 			return nil, fmt.Errorf("unknown checker type: %s", cc.Type)
 		}
+		checkersByName[name] = checkers[len(checkers)-1]
 	}
 	opts = append(opts, lookout.WithCheckers(checkers...))
+	opts = append(opts, lookout.WithEndpointCheckers(endpointCheckers...))
 
 	var samplers []sample.Sampler
+	built := make(map[string]sample.Sampler)
 	for name, sc := range c.Samplers {
-		nameOpt := sample.WithName(name)
-		switch sc.Type {
-		case saturnOrchestratorTopCids:
-			s, err := sample.NewSaturnTopCidsSampler(nameOpt)
-			if err != nil {
-				return nil, err
-			}
+		s, err := c.newSampler(name, built, make(map[string]bool))
+		if err != nil {
+			return nil, err
+		}
+		if sc.Standalone == nil || *sc.Standalone {
 			samplers = append(samplers, s)
-		case awesomeIpfsDatasets:
-			s, err := sample.NewAwesomeIpfsDatasets(nameOpt)
-			if err != nil {
-				return nil, err
+		}
+	}
+	opts = append(opts, lookout.WithSamplers(samplers...))
+
+	if len(c.Probes) != 0 {
+		if c.Publisher == nil {
+			return nil, errors.New("publisher must be configured in order to run probes")
+		}
+		popts := []probe.Option{
+			probe.WithPublisherAddrs(c.Publisher.Addrs...),
+			probe.WithProviderAddrs(c.Publisher.ProviderAddrs...),
+			probe.WithAnnounceUrls(c.Publisher.AnnounceUrls...),
+			probe.WithIdentityPath(c.Publisher.IdentityPath),
+		}
+		if c.Publisher.ListenAddr != "" {
+			popts = append(popts, probe.WithListenAddr(c.Publisher.ListenAddr))
+		}
+		if c.Publisher.Topic != "" {
+			popts = append(popts, probe.WithTopic(c.Publisher.Topic))
+		}
+		publisher, err := probe.NewPublisher(popts...)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, lookout.WithPublisher(publisher))
+
+		var probes []probe.Probe
+		for name, pc := range c.Probes {
+			checker, ok := checkersByName[pc.Checker]
+			if !ok {
+				return nil, fmt.Errorf("unknown checker %s referenced by probe %s", pc.Checker, name)
 			}
-			samplers = append(samplers, s)
-		case internetArchiveTopCids:
-			s, err := sample.NewInternetArchiveTopCidsSampler(nameOpt)
+			popts := []probe.Option{
+				probe.WithName(name),
+				probe.WithPublisher(publisher),
+				probe.WithChecker(checker),
+			}
+			if pc.Entries != 0 {
+				popts = append(popts, probe.WithEntries(pc.Entries))
+			}
+			if pc.PollInterval != 0 {
+				popts = append(popts, probe.WithPollInterval(pc.PollInterval))
+			}
+			if pc.Timeout != 0 {
+				popts = append(popts, probe.WithProbeTimeout(pc.Timeout))
+			}
+			switch pc.Type {
+			case publishFindProbe:
+				p, err := probe.NewPublishProbe(popts...)
+				if err != nil {
+					return nil, err
+				}
+				probes = append(probes, p)
+			case removalProbe:
+				p, err := probe.NewRemovalProbe(popts...)
+				if err != nil {
+					return nil, err
+				}
+				probes = append(probes, p)
+			default:
+				return nil, fmt.Errorf("unknown probe type: %s", pc.Type)
+			}
+		}
+		opts = append(opts, lookout.WithProbes(probes...))
+	}
+	if c.ProbeInterval != 0 {
+		opts = append(opts, lookout.WithProbeInterval(c.ProbeInterval))
+	}
+
+	if rs := c.ResultsSink; rs != nil {
+		switch rs.Type {
+		case boltSink:
+			s, err := sink.NewBoltSink(
+				sink.WithPath(rs.Path),
+				sink.WithMaxAge(rs.MaxAge),
+				sink.WithMaxRecords(rs.MaxRecords),
+			)
 			if err != nil {
 				return nil, err
 			}
-			samplers = append(samplers, s)
+			opts = append(opts, lookout.WithResultsSink(s))
 		default:
-			return nil, fmt.Errorf("unknown checker type: %s", sc.Type)
+			return nil, fmt.Errorf("unknown results sink type: %s", rs.Type)
 		}
 	}
-	opts = append(opts, lookout.WithSamplers(samplers...))
 
 	if c.CheckInterval != 0 {
 		opts = append(opts, lookout.WithCheckInterval(c.CheckInterval))
@@ -124,5 +366,151 @@ func (c *Config) ToOptions() ([]lookout.Option, error) {
 	if c.MetricsListenAddr != "" {
 		opts = append(opts, lookout.WithMetricsListenAddr(c.MetricsListenAddr))
 	}
+	if c.MaxCheckCids != 0 {
+		opts = append(opts, lookout.WithMaxCheckCids(c.MaxCheckCids))
+	}
+	if c.SnapshotDir != "" {
+		opts = append(opts, lookout.WithSnapshotDir(c.SnapshotDir))
+	}
+	if c.SnapshotMaxCount != nil {
+		opts = append(opts, lookout.WithSnapshotMaxCount(*c.SnapshotMaxCount))
+	}
+	if c.SnapshotMaxAge != 0 {
+		opts = append(opts, lookout.WithSnapshotMaxAge(c.SnapshotMaxAge))
+	}
 	return opts, nil
 }
+
+// newSampler instantiates the sampler with the given name, along with any samplers it references
+// as sources. Samplers already instantiated are reused from built, and visiting tracks the
+// samplers being instantiated in order to detect cyclic references.
+func (c *Config) newSampler(name string, built map[string]sample.Sampler, visiting map[string]bool) (sample.Sampler, error) {
+	if s, ok := built[name]; ok {
+		return s, nil
+	}
+	sc, ok := c.Samplers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown sampler: %s", name)
+	}
+	if visiting[name] {
+		return nil, fmt.Errorf("cyclic sampler reference: %s", name)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	sopts := []sample.Option{sample.WithName(name)}
+	if sc.MaxSize != 0 {
+		sopts = append(sopts, sample.WithMaxSetSize(sc.MaxSize))
+	}
+	if sc.Subsampling != "" {
+		sopts = append(sopts, sample.WithSubsampling(sc.Subsampling))
+	}
+	if sc.Seed != nil {
+		sopts = append(sopts, sample.WithSeed(*sc.Seed))
+	}
+	if sc.DedupeByCid {
+		sopts = append(sopts, sample.WithDedupeByCid(true))
+	}
+	var adOpts []sample.Option
+	if sc.MaxAds != 0 {
+		adOpts = append(adOpts, sample.WithMaxAds(sc.MaxAds))
+	}
+	if sc.EntriesPerAd != 0 {
+		adOpts = append(adOpts, sample.WithEntriesPerAd(sc.EntriesPerAd))
+	}
+	if sc.MaxChunksPerAd != 0 {
+		adOpts = append(adOpts, sample.WithMaxChunksPerAd(sc.MaxChunksPerAd))
+	}
+	if sc.AdSelection != "" {
+		adOpts = append(adOpts, sample.WithAdSelection(sc.AdSelection))
+	}
+
+	var s sample.Sampler
+	var err error
+	switch sc.Type {
+	case saturnOrchestratorTopCids:
+		s, err = sample.NewSaturnTopCidsSampler(sopts...)
+	case awesomeIpfsDatasets:
+		s, err = sample.NewAwesomeIpfsDatasets(sopts...)
+	case internetArchiveTopCids:
+		s, err = sample.NewInternetArchiveTopCidsSampler(sopts...)
+	case staticSampler:
+		s, err = sample.NewStaticSampler(append(sopts,
+			sample.WithFile(sc.File),
+			sample.WithFormat(sample.Format(sc.Format)),
+			sample.WithInlineCids(sc.Cids...))...)
+	case httpSampler:
+		var extractor sample.Extractor
+		switch sc.Extract.Mode {
+		case jsonPathExtractMode:
+			extractor, err = sample.NewJsonPathExtractor(sc.Extract.Path)
+		case csvColumnExtractMode:
+			extractor, err = sample.NewCsvColumnExtractor(sc.Extract.Column)
+		case regexExtractMode:
+			extractor, err = sample.NewRegexExtractor(sc.Extract.Pattern)
+		case linesExtractMode, "":
+			extractor = sample.NewLinesExtractor()
+		default:
+			err = fmt.Errorf("unknown extract mode: %s", sc.Extract.Mode)
+		}
+		if err != nil {
+			return nil, err
+		}
+		s, err = sample.NewHttpSampler(append(sopts,
+			sample.WithUrl(sc.Url),
+			sample.WithHeaders(sc.Headers),
+			sample.WithExtractor(extractor))...)
+	case adChainSampler:
+		s, err = sample.NewAdChainSampler(append(append(sopts, adOpts...), sample.WithPublisherUrl(sc.PublisherUrl))...)
+	case providersSampler:
+		popts := append(sopts, adOpts...)
+		if sc.IpniEndpoint != "" {
+			popts = append(popts, sample.WithIpniEndpoint(sc.IpniEndpoint))
+		}
+		if sc.ProviderCount != 0 {
+			popts = append(popts, sample.WithProviderCount(sc.ProviderCount))
+		}
+		if sc.ProviderWeighting != "" {
+			popts = append(popts, sample.WithProviderWeighting(sc.ProviderWeighting))
+		}
+		s, err = sample.NewProvidersSampler(popts...)
+	case replaySampler:
+		s, err = sample.NewReplaySampler(append(sopts, sample.WithSnapshot(sc.Snapshot))...)
+	case unionSampler, intersectionSampler, differenceSampler, mixSampler:
+		copts := append(sopts, sample.WithComposition(sample.Composition(sc.Type)))
+		for _, source := range sc.Sources {
+			ss, err := c.newSampler(source.Name, built, visiting)
+			if err != nil {
+				return nil, fmt.Errorf("failed to instantiate source of sampler %s: %w", name, err)
+			}
+			copts = append(copts, sample.WithSource(ss, source.Weight))
+		}
+		s, err = sample.NewCompositeSampler(copts...)
+	default:
+		err = fmt.Errorf("unknown sampler type: %s", sc.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sc.CacheTTL != 0 {
+		if s, err = sample.NewCachingSampler(s, sample.WithName(name), sample.WithCacheTTL(sc.CacheTTL)); err != nil {
+			return nil, err
+		}
+	}
+	// Subsample after caching so that unseeded samplers select different CIDs every cycle even
+	// when their source is cached.
+	if sc.MaxSize != 0 {
+		if s, err = sample.NewSubsamplingSampler(s, sopts...); err != nil {
+			return nil, err
+		}
+	}
+	// Resolve paths after subsampling so that only the paths of selected CIDs are resolved. The
+	// leaf CIDs added by resolution are deliberately not capped by MaxSize.
+	if sc.PathGateway != "" {
+		if s, err = sample.NewPathResolvingSampler(s, sample.WithName(name), sample.WithPathGateway(sc.PathGateway)); err != nil {
+			return nil, err
+		}
+	}
+	built[name] = s
+	return s, nil
+}
@@ -0,0 +1,232 @@
+package lookout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipni/lookout/check"
+	"github.com/ipni/lookout/perform"
+	"github.com/ipni/lookout/sample"
+	"github.com/ipni/lookout/sink"
+)
+
+// adHocSampleSetName is the name of sample sets constructed from CIDs supplied via HTTP API.
+const adHocSampleSetName = "ad-hoc"
+
+type (
+	cycleResponse struct {
+		ID string `json:"id"`
+	}
+	checkResponse struct {
+		Checkers []*checkerResults `json:"checkers"`
+	}
+	checkerResults struct {
+		Checker string          `json:"checker"`
+		Results []*check.Result `json:"results"`
+	}
+	named interface {
+		Name() string
+	}
+)
+
+// handleCycle triggers an ad-hoc cycle, optionally limited to the checkers and samplers named by
+// the "checker" and "sampler" query parameters respectively. Responds with the ID of the cycle.
+func (l *Lookout) handleCycle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	checkers, endpointCheckers, err := l.selectCheckers(query["checker"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unknown checker: %s", err), http.StatusBadRequest)
+		return
+	}
+	samplers, err := selectNamed(l.samplers, query["sampler"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unknown sampler: %s", err), http.StatusBadRequest)
+		return
+	}
+	c := l.newCycle(checkers, endpointCheckers, samplers)
+	select {
+	case l.cycles <- c:
+		logger.Infow("Ad-hoc cycle requested", "cycle", c.id, "checkers", query["checker"], "samplers", query["sampler"])
+	default:
+		http.Error(w, "too many pending cycles", http.StatusServiceUnavailable)
+		return
+	}
+	writeJson(w, http.StatusAccepted, &cycleResponse{ID: c.id})
+}
+
+// handleCheck runs the checkers against the CIDs specified by "cid" query parameter and responds
+// with the results of every checker. At most maxCheckCids CIDs can be specified. Checkers can
+// optionally be limited by name via "checker" query parameter. The results are not reported as
+// metrics.
+func (l *Lookout) handleCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	var cids []cid.Cid
+	for _, param := range query["cid"] {
+		for _, v := range strings.Split(param, ",") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			if len(cids) == l.maxCheckCids {
+				http.Error(w, fmt.Sprintf("too many cids: at most %d can be specified", l.maxCheckCids), http.StatusBadRequest)
+				return
+			}
+			c, err := sample.DecodeCidOrMultihash(v)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid cid %s: %s", v, err), http.StatusBadRequest)
+				return
+			}
+			cids = append(cids, c)
+		}
+	}
+	if len(cids) == 0 {
+		http.Error(w, "at least one cid must be specified", http.StatusBadRequest)
+		return
+	}
+	checkers, err := selectNamed(l.checkers, query["checker"])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unknown checker: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	set := &sample.Set{Name: adHocSampleSetName, Cids: cids}
+	results := perform.InParallel(r.Context(), l.checkersParallelism, checkers, func(ctx context.Context, c check.Checker) *check.Results {
+		return c.Check(ctx, set)
+	})
+	var resp checkResponse
+	for rs := range results {
+		resp.Checkers = append(resp.Checkers, &checkerResults{
+			Checker: rs.CheckerName,
+			Results: rs.Results,
+		})
+	}
+	if err := r.Context().Err(); err != nil {
+		logger.Warnw("Ad-hoc check request cancelled", "err", err)
+		return
+	}
+	writeJson(w, http.StatusOK, &resp)
+}
+
+// handleResults responds with the results recorded in the results sink, most recent first. Records
+// can be filtered via "cycle", "sampler", "checker", "multihash", "from" and "to" query
+// parameters, where "from" and "to" are RFC 3339 timestamps. The number of records is limited by
+// "limit" query parameter, which defaults to 1000.
+func (l *Lookout) handleResults(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	q := &sink.Query{
+		CycleID:   query.Get("cycle"),
+		Sampler:   query.Get("sampler"),
+		Checker:   query.Get("checker"),
+		Multihash: query.Get("multihash"),
+		Limit:     1000,
+	}
+	if v := query.Get("cid"); v != "" {
+		c, err := sample.DecodeCidOrMultihash(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid cid %s: %s", v, err), http.StatusBadRequest)
+			return
+		}
+		q.Multihash = c.Hash().B58String()
+	}
+	var err error
+	if v := query.Get("from"); v != "" {
+		if q.From, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, fmt.Sprintf("invalid from: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		if q.To, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, fmt.Sprintf("invalid to: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil || q.Limit < 1 {
+			http.Error(w, "invalid limit: must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+	records, err := l.resultsSink.(sink.Querier).Query(r.Context(), q)
+	if err != nil {
+		logger.Errorw("Failed to query results sink", "err", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if records == nil {
+		records = []*sink.StoredRecord{}
+	}
+	writeJson(w, http.StatusOK, records)
+}
+
+// selectNamed selects the items with the given names, or all items if no names are given.
+// Items that do not expose a name via Name() string method cannot be selected by name.
+func selectNamed[T any](items []T, names []string) ([]T, error) {
+	if len(names) == 0 {
+		return items, nil
+	}
+	byName := make(map[string]T, len(items))
+	for _, item := range items {
+		if n, ok := any(item).(named); ok {
+			byName[n.Name()] = item
+		}
+	}
+	selected := make([]T, 0, len(names))
+	for _, name := range names {
+		item, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%s", name)
+		}
+		selected = append(selected, item)
+	}
+	return selected, nil
+}
+
+// selectCheckers selects the checkers and endpoint checkers with the given names, or all of them
+// if no names are given.
+func (l *Lookout) selectCheckers(names []string) ([]check.Checker, []check.EndpointChecker, error) {
+	if len(names) == 0 {
+		return l.checkers, l.endpointCheckers, nil
+	}
+	var checkers []check.Checker
+	var endpointCheckers []check.EndpointChecker
+	for _, name := range names {
+		if c, err := selectNamed(l.checkers, []string{name}); err == nil {
+			checkers = append(checkers, c...)
+		} else if ec, err := selectNamed(l.endpointCheckers, []string{name}); err == nil {
+			endpointCheckers = append(endpointCheckers, ec...)
+		} else {
+			return nil, nil, err
+		}
+	}
+	return checkers, endpointCheckers, nil
+}
+
+func writeJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Errorw("Failed to write response", "err", err)
+	}
+}
@@ -0,0 +1,179 @@
+// Package ad offers the IPNI advertisement and entry chunk types along with their DAG-JSON
+// representation.
+//
+// See: https://github.com/ipni/specs/blob/main/IPNI.md#advertisements
+package ad
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+var (
+	// NoEntries is the CID used as advertisement entries link when an advertisement has no entries,
+	// e.g. when it only updates provider metadata.
+	NoEntries = cid.NewCidV1(cid.Raw, mustIdentity())
+
+	ErrUnsupportedCodec = errors.New("unsupported codec")
+)
+
+type (
+	// Advertisement is an IPNI advertisement. Fields are declared in the order at which they
+	// appear in DAG-JSON, i.e. sorted by name.
+	Advertisement struct {
+		Addresses  []string
+		ContextID  Bytes
+		Entries    cid.Cid
+		IsRm       bool
+		Metadata   Bytes
+		PreviousID *cid.Cid `json:",omitempty"`
+		Provider   string
+		Signature  Bytes
+	}
+	// EntryChunk is a chunk of multihashes advertised by an advertisement, linked to the next
+	// chunk if any. Fields are declared in the order at which they appear in DAG-JSON.
+	EntryChunk struct {
+		Entries []Bytes
+		Next    *cid.Cid `json:",omitempty"`
+	}
+	// Bytes is a byte slice represented in DAG-JSON as {"/":{"bytes":"<base64>"}}.
+	Bytes []byte
+)
+
+// HasEntries checks whether the advertisement links to any entries.
+func (a *Advertisement) HasEntries() bool {
+	return a.Entries.Defined() && !a.Entries.Equals(NoEntries)
+}
+
+// SignaturePayload returns the payload signed by the provider of the advertisement, i.e. the
+// SHA2-256 multihash of the concatenated previous ID, entries, provider, addresses, metadata and
+// removal flag.
+func (a *Advertisement) SignaturePayload() []byte {
+	var buf bytes.Buffer
+	if a.PreviousID != nil {
+		buf.Write(a.PreviousID.Bytes())
+	}
+	buf.Write(a.Entries.Bytes())
+	buf.WriteString(a.Provider)
+	for _, addr := range a.Addresses {
+		buf.WriteString(addr)
+	}
+	buf.Write(a.Metadata)
+	if a.IsRm {
+		buf.WriteByte(1)
+	} else {
+		buf.WriteByte(0)
+	}
+	digest := sha256.Sum256(buf.Bytes())
+	// Encoding a SHA2-256 digest as a multihash cannot fail.
+	mh, _ := multihash.Encode(digest[:], multihash.SHA2_256)
+	return mh
+}
+
+// Encode encodes the given advertisement or entry chunk as a DAG-JSON block, and returns the
+// block along with its CID.
+func Encode(v any) (cid.Cid, []byte, error) {
+	if a, ok := v.(*Advertisement); ok && a.Addresses == nil {
+		// Addresses is a required list; represent it as empty rather than null.
+		withAddrs := *a
+		withAddrs.Addresses = []string{}
+		v = &withAddrs
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return cid.Undef, nil, err
+	}
+	data := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	c, err := cid.NewPrefixV1(cid.DagJSON, multihash.SHA2_256).Sum(data)
+	if err != nil {
+		return cid.Undef, nil, err
+	}
+	return c, data, nil
+}
+
+// DecodeAdvertisement decodes the given block as an advertisement, verifying that it matches the
+// given CID. Only DAG-JSON encoded blocks are supported.
+func DecodeAdvertisement(c cid.Cid, data []byte) (*Advertisement, error) {
+	var a Advertisement
+	if err := decode(c, data, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// DecodeEntryChunk decodes the given block as an entry chunk, verifying that it matches the given
+// CID. Only DAG-JSON encoded blocks are supported.
+func DecodeEntryChunk(c cid.Cid, data []byte) (*EntryChunk, error) {
+	var ec EntryChunk
+	if err := decode(c, data, &ec); err != nil {
+		return nil, err
+	}
+	return &ec, nil
+}
+
+// Multihashes returns the multihashes in the entry chunk, skipping any that fail to decode.
+func (ec *EntryChunk) Multihashes() []multihash.Multihash {
+	mhs := make([]multihash.Multihash, 0, len(ec.Entries))
+	for _, entry := range ec.Entries {
+		if _, err := multihash.Cast(entry); err != nil {
+			continue
+		}
+		mhs = append(mhs, multihash.Multihash(entry))
+	}
+	return mhs
+}
+
+func decode(c cid.Cid, data []byte, v any) error {
+	if c.Prefix().Codec != cid.DagJSON {
+		return fmt.Errorf("%w: 0x%x", ErrUnsupportedCodec, c.Prefix().Codec)
+	}
+	actual, err := c.Prefix().Sum(data)
+	if err != nil {
+		return err
+	}
+	if !actual.Equals(c) {
+		return fmt.Errorf("block does not match its CID: expected %s but got %s", c, actual)
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]map[string]string{
+		"/": {"bytes": base64.RawStdEncoding.EncodeToString(b)},
+	})
+}
+
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Slash struct {
+			Bytes string `json:"bytes"`
+		} `json:"/"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	decoded, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(v.Slash.Bytes, "="))
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+func mustIdentity() multihash.Multihash {
+	mh, err := multihash.Sum(nil, multihash.IDENTITY, -1)
+	if err != nil {
+		panic(err)
+	}
+	return mh
+}
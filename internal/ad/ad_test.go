@@ -0,0 +1,28 @@
+package ad
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+)
+
+func TestAdvertisement_SignaturePayload(t *testing.T) {
+	entries, err := cid.Decode("baguqeeraxwcivsdsb7lv7w6tpusrwnr6v5oeaws5mhsjfbi4i2bwrup4coca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Advertisement{
+		Addresses: []string{"/dns4/example.com/tcp/443/https"},
+		ContextID: []byte("lookout"),
+		Entries:   entries,
+		Metadata:  binary.AppendUvarint(nil, 0x0920),
+		Provider:  "12D3KooWBtg3aaRMjxwedh83aGiUkwSxDwUZkzuJcfaqUmo7R3pq",
+	}
+	// SHA2-256 multihash of the concatenated fields, computed independently of this package.
+	const want = "1220e476c75806b92e2be80317ca0a7252f08306f6bf3a902543e291b88ef7181bf2"
+	if got := hex.EncodeToString(a.SignaturePayload()); got != want {
+		t.Fatalf("expected signature payload %s; got %s", want, got)
+	}
+}
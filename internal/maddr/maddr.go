@@ -0,0 +1,192 @@
+// Package maddr offers minimal parsing of textual multiaddrs, enough to reach the TCP and HTTP
+// endpoints advertised by IPNI providers and publishers.
+package maddr
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mr-tron/base58"
+)
+
+// Multicodec codes of the multiaddr protocols supported by Encode.
+// See: https://github.com/multiformats/multiaddr/blob/master/protocols.csv
+const (
+	codeIp4      = 0x04
+	codeTcp      = 0x06
+	codeIp6      = 0x29
+	codeDns      = 0x35
+	codeDns4     = 0x36
+	codeDns6     = 0x37
+	codeDnsaddr  = 0x38
+	codeP2p      = 0x01a5
+	codeHttps    = 0x01bb
+	codeTls      = 0x01c0
+	codeHttp     = 0x01e0
+	codeHttpPath = 0x01e1
+)
+
+type Addr struct {
+	// Host is the IP address or DNS name of the address.
+	Host string
+	// Port is the TCP port of the address, if any.
+	Port string
+	// Scheme is either "http" or "https" if the address is an HTTP address, or empty otherwise.
+	Scheme string
+	// PeerID is the value of the p2p component of the address, if any.
+	PeerID string
+	// Path is the unescaped value of the http-path component of the address, if any, e.g.
+	// /ipni/v1 for http-path %2Fipni%2Fv1.
+	Path string
+}
+
+// Parse parses the given textual multiaddr, e.g. /dns4/example.com/tcp/443/https. Addresses that do
+// not specify a host and a TCP port, such as QUIC addresses, are rejected.
+func Parse(s string) (*Addr, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "/"), "/")
+	var a Addr
+	var tls bool
+	for i := 0; i < len(parts); i++ {
+		next := func() (string, error) {
+			if i+1 >= len(parts) {
+				return "", fmt.Errorf("missing value for %s in multiaddr %s", parts[i], s)
+			}
+			i++
+			return parts[i], nil
+		}
+		var err error
+		switch parts[i] {
+		case "ip4", "ip6", "dns", "dns4", "dns6", "dnsaddr":
+			a.Host, err = next()
+		case "tcp":
+			a.Port, err = next()
+		case "p2p", "ipfs":
+			a.PeerID, err = next()
+		case "tls":
+			tls = true
+		case "http":
+			a.Scheme = "http"
+		case "https":
+			a.Scheme = "https"
+		case "http-path":
+			var escaped string
+			if escaped, err = next(); err == nil {
+				if a.Path, err = url.PathUnescape(escaped); err != nil {
+					err = fmt.Errorf("invalid http-path %s in multiaddr %s: %w", escaped, s, err)
+				}
+			}
+		case "udp", "quic", "quic-v1", "webtransport", "ws", "wss":
+			return nil, fmt.Errorf("unsupported multiaddr transport %s in %s", parts[i], s)
+		default:
+			return nil, fmt.Errorf("unknown multiaddr protocol %s in %s", parts[i], s)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if tls && a.Scheme == "http" {
+		a.Scheme = "https"
+	}
+	if a.Host == "" || a.Port == "" {
+		return nil, errors.New("multiaddr must specify host and TCP port: " + s)
+	}
+	return &a, nil
+}
+
+// HostPort returns the host and port of the address joined together, ready to be dialled.
+func (a *Addr) HostPort() string {
+	return net.JoinHostPort(a.Host, a.Port)
+}
+
+// IsHTTP checks whether the address is an HTTP address.
+func (a *Addr) IsHTTP() bool {
+	return a.Scheme != ""
+}
+
+// URL returns the HTTP URL of the address, including its http-path if any. The default ports for
+// http and https are omitted.
+func (a *Addr) URL() (*url.URL, error) {
+	if !a.IsHTTP() {
+		return nil, errors.New("not an HTTP multiaddr")
+	}
+	host := a.HostPort()
+	if (a.Scheme == "http" && a.Port == "80") || (a.Scheme == "https" && a.Port == "443") {
+		host = a.Host
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+	}
+	return &url.URL{Scheme: a.Scheme, Host: host, Path: a.Path}, nil
+}
+
+// Encode encodes the given textual multiaddr into its binary representation. Only the protocols
+// accepted by Parse are supported.
+func Encode(s string) ([]byte, error) {
+	if _, err := Parse(s); err != nil {
+		return nil, err
+	}
+	parts := strings.Split(strings.TrimPrefix(s, "/"), "/")
+	var b []byte
+	for i := 0; i < len(parts); i++ {
+		protocol := parts[i]
+		var value string
+		switch protocol {
+		case "ip4", "ip6", "dns", "dns4", "dns6", "dnsaddr", "tcp", "p2p", "ipfs", "http-path":
+			// Presence of the value is validated by Parse.
+			i++
+			value = parts[i]
+		}
+		switch protocol {
+		case "ip4", "ip6":
+			ip := net.ParseIP(value)
+			code := codeIp6
+			if protocol == "ip4" {
+				ip = ip.To4()
+				code = codeIp4
+			}
+			if ip == nil {
+				return nil, fmt.Errorf("invalid %s address %s in multiaddr %s", protocol, value, s)
+			}
+			b = binary.AppendUvarint(b, uint64(code))
+			b = append(b, ip...)
+		case "dns", "dns4", "dns6", "dnsaddr":
+			code := map[string]uint64{"dns": codeDns, "dns4": codeDns4, "dns6": codeDns6, "dnsaddr": codeDnsaddr}[protocol]
+			b = binary.AppendUvarint(b, code)
+			b = binary.AppendUvarint(b, uint64(len(value)))
+			b = append(b, value...)
+		case "tcp":
+			port, err := strconv.ParseUint(value, 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("invalid TCP port %s in multiaddr %s", value, s)
+			}
+			b = binary.AppendUvarint(b, codeTcp)
+			b = binary.BigEndian.AppendUint16(b, uint16(port))
+		case "p2p", "ipfs":
+			id, err := base58.Decode(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid peer ID %s in multiaddr %s: %w", value, s, err)
+			}
+			b = binary.AppendUvarint(b, codeP2p)
+			b = binary.AppendUvarint(b, uint64(len(id)))
+			b = append(b, id...)
+		case "tls":
+			b = binary.AppendUvarint(b, codeTls)
+		case "http":
+			b = binary.AppendUvarint(b, codeHttp)
+		case "https":
+			b = binary.AppendUvarint(b, codeHttps)
+		case "http-path":
+			// Validity of the escaping is checked by Parse.
+			path, _ := url.PathUnescape(value)
+			b = binary.AppendUvarint(b, codeHttpPath)
+			b = binary.AppendUvarint(b, uint64(len(path)))
+			b = append(b, path...)
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,78 @@
+package maddr
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	// Expected encodings are computed from the multiaddr protocol table, independently of Encode.
+	for addr, want := range map[string]string{
+		"/ip4/127.0.0.1/tcp/4001":                                "047f000001060fa1",
+		"/ip6/::1/tcp/80/http":                                   "2900000000000000000000000000000001060050e003",
+		"/dns/ipni.example/tcp/8443/tls/http":                    "350c69706e692e6578616d706c650620fbc003e003",
+		"/dns4/example.com/tcp/443/https/http-path/%2Fipni%2Fv1": "360b6578616d706c652e636f6d0601bbbb03e103082f69706e692f7631",
+		"/dns4/example.com/tcp/443/https/p2p/12D3KooWBtg3aaRMjxwedh83aGiUkwSxDwUZkzuJcfaqUmo7R3pq": "360b6578616d706c652e636f6d0601bbbb03a503260024080112201ed1e8fae2c4a144b8be8fd4b47bf3d3b34b871c3cacf6010f0e42d474fce27e",
+	} {
+		t.Run(addr, func(t *testing.T) {
+			got, err := Encode(addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(got) != want {
+				t.Fatalf("expected %s; got %x", want, got)
+			}
+		})
+	}
+}
+
+func TestEncode_Invalid(t *testing.T) {
+	for _, addr := range []string{
+		"/ip4/127.0.0.1",
+		"/ip4/::1/tcp/80",
+		"/ip4/127.0.0.1/tcp/65536",
+		"/ip4/127.0.0.1/udp/4001/quic",
+		"/dns4/example.com/tcp/443/p2p/not-a-peer-id",
+		"/dns4/example.com/tcp/443/https/http-path/%zz",
+		"/dns4/example.com/tcp/443/https/http-path",
+	} {
+		t.Run(addr, func(t *testing.T) {
+			if _, err := Encode(addr); err == nil {
+				t.Fatalf("expected encoding %s to fail", addr)
+			}
+		})
+	}
+}
+
+func TestParse_URL(t *testing.T) {
+	for addr, want := range map[string]string{
+		"/dns4/example.com/tcp/443/https":                        "https://example.com",
+		"/dns4/example.com/tcp/8080/http":                        "http://example.com:8080",
+		"/ip4/127.0.0.1/tcp/443/tls/http":                        "https://127.0.0.1",
+		"/ip6/::1/tcp/80/http":                                   "http://[::1]",
+		"/dns4/example.com/tcp/443/https/http-path/%2Fipni%2Fv1": "https://example.com/ipni/v1",
+		"/dns4/example.com/tcp/443/tls/http/http-path/a%2Fb%20c": "https://example.com/a/b%20c",
+	} {
+		t.Run(addr, func(t *testing.T) {
+			ma, err := Parse(addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			u, err := ma.URL()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.String() != want {
+				t.Fatalf("expected URL %s; got %s", want, u)
+			}
+		})
+	}
+
+	ma, err := Parse("/ip4/127.0.0.1/tcp/4001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ma.URL(); err == nil {
+		t.Fatal("expected error getting URL of non-HTTP multiaddr")
+	}
+}
@@ -2,14 +2,20 @@ package lookout
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"net"
 	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/ipfs/go-log/v2"
 	"github.com/ipni/lookout/check"
 	"github.com/ipni/lookout/metrics"
 	"github.com/ipni/lookout/perform"
+	"github.com/ipni/lookout/probe"
 	"github.com/ipni/lookout/sample"
+	"github.com/ipni/lookout/sink"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -22,6 +28,22 @@ type (
 		*options
 		s       *http.Server
 		metrics *metrics.Metrics
+		cycles  chan *cycle
+		// checkingEndpoints signals whether endpoint checkers of a cycle are still running.
+		checkingEndpoints atomic.Bool
+	}
+	// cycle represents a single run of samplers, the sample sets of which are checked by checkers,
+	// along with a single run of endpoint checkers.
+	cycle struct {
+		id               string
+		checkers         []check.Checker
+		endpointCheckers []check.EndpointChecker
+		samplers         []sample.Sampler
+	}
+	// cycleSet is a sample set produced as part of a cycle.
+	cycleSet struct {
+		*cycle
+		set *sample.Set
 	}
 )
 
@@ -38,6 +60,7 @@ func New(o ...Option) (*Lookout, error) {
 		TLSConfig: nil,
 	}
 	l.metrics = metrics.New()
+	l.cycles = make(chan *cycle, l.maxPendingCycles)
 	return &l, nil
 }
 
@@ -51,31 +74,41 @@ func (l *Lookout) Start(ctx context.Context) error {
 	}
 	go func() { _ = l.s.Serve(ln) }()
 
+	if l.publisher != nil {
+		if err := l.publisher.Start(ctx); err != nil {
+			return err
+		}
+	}
+
 	wctx, cancel := context.WithCancel(context.Background())
-	ssch := make(chan *sample.Set)
+	ssch := make(chan *cycleSet)
 	go l.sample(wctx, ssch)
 	go l.check(wctx, ssch)
+	for _, p := range l.probes {
+		go l.probe(wctx, p)
+	}
 	l.s.RegisterOnShutdown(cancel)
 
 	logger.Infow("Server started", "httpAddr", ln.Addr())
 	return nil
 }
 
-func (l *Lookout) check(ctx context.Context, targets <-chan *sample.Set) {
+func (l *Lookout) check(ctx context.Context, targets <-chan *cycleSet) {
 	for {
 		select {
 		case <-ctx.Done():
 			logger.Info("Checkers cycle stopped", "err", ctx.Err())
 			return
-		case ss, ok := <-targets:
+		case cs, ok := <-targets:
 			if !ok {
 				logger.Info("Checkers cycle stopped; no more work")
 				return
 			}
-			logger := logger.With("size", len(ss.Cids), "name", ss.Name)
+			ss := cs.set
+			logger := logger.With("size", len(ss.Cids), "name", ss.Name, "cycle", cs.id)
 			logger.Info("Running checks on sample set...")
 
-			results := perform.InParallel(ctx, l.checkersParallelism, l.checkers, func(ctx context.Context, c check.Checker) *check.Results {
+			results := perform.InParallel(ctx, l.checkersParallelism, cs.checkers, func(ctx context.Context, c check.Checker) *check.Results {
 				return c.Check(ctx, ss)
 			})
 			go func() {
@@ -90,6 +123,11 @@ func (l *Lookout) check(ctx context.Context, targets <-chan *sample.Set) {
 							return
 						}
 						l.metrics.NotifyCheckResults(ctx, r)
+						if l.resultsSink != nil {
+							if err := l.resultsSink.Put(ctx, sink.NewRecords(cs.id, r, time.Now())...); err != nil {
+								logger.Errorw("Failed to record check results.", "checker", r.CheckerName, "err", err)
+							}
+						}
 					}
 				}
 			}()
@@ -97,9 +135,64 @@ func (l *Lookout) check(ctx context.Context, targets <-chan *sample.Set) {
 	}
 }
 
-func (l *Lookout) sample(ctx context.Context, check chan<- *sample.Set) {
-	runCycle := func() {
-		sets := perform.InParallel(ctx, l.samplersParallelism, l.samplers, func(ctx context.Context, s sample.Sampler) *sample.Set {
+// checkEndpoints runs the endpoint checkers of the given cycle and reports their results.
+func (l *Lookout) checkEndpoints(ctx context.Context, c *cycle) {
+	results := perform.InParallel(ctx, l.checkersParallelism, c.endpointCheckers, func(ctx context.Context, ec check.EndpointChecker) check.EndpointResults {
+		return ec.CheckEndpoint(ctx)
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case r, ok := <-results:
+			if !ok {
+				return
+			}
+			logger.Infow("Endpoint check finished.", "cycle", c.id, "checker", r.Checker())
+			l.metrics.NotifyEndpointResults(ctx, r)
+		}
+	}
+}
+
+// probe runs the given probe at the configured probe interval until the context is done.
+func (l *Lookout) probe(ctx context.Context, p probe.Probe) {
+	ticker := time.NewTicker(l.probeInterval)
+	defer ticker.Stop()
+	for {
+		r := p.Probe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		logger := logger.With("probe", r.ProbeName, "kind", r.Kind, "ad", r.Advertisement)
+		if r.Succeeded {
+			logger.Infow("Probe succeeded.", "elapsed", r.Elapsed)
+		} else {
+			logger.Warnw("Probe failed.", "elapsed", r.Elapsed, "err", r.Err)
+		}
+		l.metrics.NotifyProbeResult(ctx, r)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *Lookout) sample(ctx context.Context, check chan<- *cycleSet) {
+	runCycle := func(c *cycle) {
+		logger := logger.With("cycle", c.id)
+		logger.Infow("Starting cycle", "samplers", len(c.samplers), "checkers", len(c.checkers), "endpointCheckers", len(c.endpointCheckers))
+		// Skip endpoint checks rather than overlap them with a previous run that is taking longer
+		// than the check interval.
+		if l.checkingEndpoints.CompareAndSwap(false, true) {
+			go func() {
+				defer l.checkingEndpoints.Store(false)
+				l.checkEndpoints(ctx, c)
+			}()
+		} else if len(c.endpointCheckers) != 0 {
+			logger.Warnw("Skipped endpoint checks; previous run is still in progress.")
+		}
+		sets := perform.InParallel(ctx, l.samplersParallelism, c.samplers, func(ctx context.Context, s sample.Sampler) *sample.Set {
 			ss, err := s.Sample(ctx)
 			if err != nil {
 				logger.Errorw("Failed to sample.", "err", err)
@@ -118,36 +211,88 @@ func (l *Lookout) sample(ctx context.Context, check chan<- *sample.Set) {
 				if set == nil {
 					continue
 				}
-				logger.Infow("Selected samples", "count", len(set.Cids), "name", set.Name)
+				logger.Infow("Selected samples", "count", len(set.Cids), "name", set.Name, "provider", set.Provider)
 				l.metrics.NotifySampleSet(ctx, set)
+				if l.snapshotDir != "" {
+					l.snapshot(c, set)
+				}
 				select {
 				case <-ctx.Done():
 					return
-				case check <- set:
+				case check <- &cycleSet{cycle: c, set: set}:
 				}
 			}
 		}
 	}
-	runCycle()
+	runCycle(l.newCycle(l.checkers, l.endpointCheckers, l.samplers))
 	for {
 		select {
 		case <-ctx.Done():
 			logger.Info("Monitoring stopped", "err", ctx.Err())
 			return
 		case <-l.checkInterval.C:
-			runCycle()
+			runCycle(l.newCycle(l.checkers, l.endpointCheckers, l.samplers))
+		case c := <-l.cycles:
+			runCycle(c)
 		}
 	}
 }
 
+// snapshot writes the given sample set as a snapshot, and prunes snapshots of the same set that
+// exceed the configured retention.
+func (l *Lookout) snapshot(c *cycle, set *sample.Set) {
+	now := time.Now()
+	p, err := sample.WriteSnapshot(l.snapshotDir, c.id, set, now)
+	if err != nil {
+		logger.Errorw("Failed to write sample set snapshot.", "cycle", c.id, "name", set.Name, "err", err)
+		return
+	}
+	logger.Debugw("Wrote sample set snapshot.", "cycle", c.id, "name", set.Name, "path", p)
+	removed, err := sample.PruneSnapshots(l.snapshotDir, set.Name, l.snapshotMaxCount, l.snapshotMaxAge, now)
+	if err != nil {
+		logger.Errorw("Failed to prune sample set snapshots.", "cycle", c.id, "name", set.Name, "err", err)
+		return
+	}
+	if removed > 0 {
+		logger.Debugw("Pruned sample set snapshots.", "cycle", c.id, "name", set.Name, "removed", removed)
+	}
+}
+
+// newCycle instantiates a new cycle with a random ID.
+func (l *Lookout) newCycle(checkers []check.Checker, endpointCheckers []check.EndpointChecker, samplers []sample.Sampler) *cycle {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return &cycle{
+		id:               hex.EncodeToString(id),
+		checkers:         checkers,
+		endpointCheckers: endpointCheckers,
+		samplers:         samplers,
+	}
+}
+
 func (l *Lookout) serveMux() *http.ServeMux {
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/cycle", l.handleCycle)
+	mux.HandleFunc("/check", l.handleCheck)
+	if _, ok := l.resultsSink.(sink.Querier); ok {
+		mux.HandleFunc("/results", l.handleResults)
+	}
 	return mux
 }
 
 func (l *Lookout) Shutdown(ctx context.Context) error {
 	serr := l.s.Shutdown(ctx)
+	if l.publisher != nil {
+		if err := l.publisher.Shutdown(ctx); err != nil {
+			logger.Warnw("Failed to shut down publisher.", "err", err)
+		}
+	}
 	_ = l.metrics.Shutdown(ctx)
+	if l.resultsSink != nil {
+		if err := l.resultsSink.Close(); err != nil {
+			logger.Warnw("Failed to close results sink.", "err", err)
+		}
+	}
 	return serr
 }
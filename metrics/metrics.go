@@ -2,10 +2,11 @@ package metrics
 
 import (
 	"context"
-	"net/http"
 	"sync"
+	"time"
 
 	"github.com/ipni/lookout/check"
+	"github.com/ipni/lookout/probe"
 	"github.com/ipni/lookout/sample"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/prometheus"
@@ -16,19 +17,52 @@ import (
 type Metrics struct {
 	exporter *prometheus.Exporter
 
-	checkLatencyHistogram   instrument.Int64Histogram
-	sampleSetSizeGauge      instrument.Int64ObservableGauge
-	lookupSuccessRatioGauge instrument.Float64ObservableGauge
+	checkLatencyHistogram             instrument.Int64Histogram
+	checkTimeToFirstProviderHistogram instrument.Int64Histogram
+	checkProviderCountHistogram       instrument.Int64Histogram
+	checkFailuresCounter              instrument.Int64Counter
+	sampleSetSizeGauge                instrument.Int64ObservableGauge
+	lookupSuccessRatioGauge           instrument.Float64ObservableGauge
+	retrievabilityRatioGauge          instrument.Float64ObservableGauge
+	endpointDisagreementRatioGauge    instrument.Float64ObservableGauge
+	sampleSetAgeGauge                 instrument.Float64ObservableGauge
+	probeTimeToFindableHistogram      instrument.Int64Histogram
+	probeTimeToUnfindableHistogram    instrument.Int64Histogram
+	probeSuccessGauge                 instrument.Int64ObservableGauge
+	providerLastAdTimeGauge           instrument.Int64ObservableGauge
+	providerLastAdAgeGauge            instrument.Float64ObservableGauge
+	providerAdLagGauge                instrument.Int64ObservableGauge
+	providerFrozenGauge               instrument.Int64ObservableGauge
+	providerErrorGauge                instrument.Int64ObservableGauge
+	providerPublisherReachableGauge   instrument.Int64ObservableGauge
+	providerInfoAvailableGauge        instrument.Int64ObservableGauge
+	endpointHealthyGauge              instrument.Int64ObservableGauge
+	endpointEntriesEstimateGauge      instrument.Int64ObservableGauge
+	endpointEntriesCountGauge         instrument.Int64ObservableGauge
+	endpointProviderCountGauge        instrument.Int64ObservableGauge
+	endpointHealthLatencyGauge        instrument.Int64ObservableGauge
 
-	observablesLock     sync.RWMutex
-	sampleSetSizes      map[string]int64
-	lookupSuccessRatios map[attribute.Set]float64
+	observablesLock      sync.RWMutex
+	sampleSetSizes       map[string]int64
+	sampleSetSampledAt   map[string]time.Time
+	lookupSuccessRatios  map[attribute.Set]float64
+	retrievabilityRatios map[attribute.Set]float64
+	disagreementRatios   map[attribute.Set]float64
+	probeSuccesses       map[attribute.Set]int64
+	providerHealths      map[attribute.Set]*check.ProviderHealth
+	indexerStatuses      map[string]*check.IndexerStatusResults
 }
 
 func New() *Metrics {
 	return &Metrics{
-		sampleSetSizes:      make(map[string]int64),
-		lookupSuccessRatios: make(map[attribute.Set]float64),
+		sampleSetSizes:       make(map[string]int64),
+		sampleSetSampledAt:   make(map[string]time.Time),
+		lookupSuccessRatios:  make(map[attribute.Set]float64),
+		retrievabilityRatios: make(map[attribute.Set]float64),
+		disagreementRatios:   make(map[attribute.Set]float64),
+		probeSuccesses:       make(map[attribute.Set]int64),
+		providerHealths:      make(map[attribute.Set]*check.ProviderHealth),
+		indexerStatuses:      make(map[string]*check.IndexerStatusResults),
 	}
 }
 
@@ -50,6 +84,27 @@ func (m *Metrics) Start() error {
 	); err != nil {
 		return err
 	}
+	if m.checkTimeToFirstProviderHistogram, err = meter.Int64Histogram(
+		"ipni/lookout/check_time_to_first_provider",
+		instrument.WithUnit("ms"),
+		instrument.WithDescription("The elapsed time to the first provider record per streaming check in milliseconds."),
+	); err != nil {
+		return err
+	}
+	if m.checkProviderCountHistogram, err = meter.Int64Histogram(
+		"ipni/lookout/check_provider_count",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("The number of providers returned per check by transfer protocol."),
+	); err != nil {
+		return err
+	}
+	if m.checkFailuresCounter, err = meter.Int64Counter(
+		"ipni/lookout/check_failures",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("The number of failed checks by failure reason."),
+	); err != nil {
+		return err
+	}
 	if m.sampleSetSizeGauge, err = meter.Int64ObservableCounter(
 		"ipni/lookout/sample_set_size",
 		instrument.WithUnit("1"),
@@ -58,6 +113,14 @@ func (m *Metrics) Start() error {
 	); err != nil {
 		return err
 	}
+	if m.sampleSetAgeGauge, err = meter.Float64ObservableGauge(
+		"ipni/lookout/sample_set_age",
+		instrument.WithUnit("s"),
+		instrument.WithDescription("The time elapsed since the last sample set returned by samplers was sampled from its source in seconds."),
+		instrument.WithFloat64Callback(m.observeSampleSetAge),
+	); err != nil {
+		return err
+	}
 	if m.lookupSuccessRatioGauge, err = meter.Float64ObservableGauge(
 		"ipni/lookout/lookup_success_ratio",
 		instrument.WithUnit("%"),
@@ -66,6 +129,140 @@ func (m *Metrics) Start() error {
 	); err != nil {
 		return err
 	}
+	if m.retrievabilityRatioGauge, err = meter.Float64ObservableGauge(
+		"ipni/lookout/retrievability_ratio",
+		instrument.WithUnit("%"),
+		instrument.WithDescription("The ratio of successful retrievability probes per provider and transfer protocol as a number between 0 and 1."),
+		instrument.WithFloat64Callback(m.observeRetrievabilityRatio),
+	); err != nil {
+		return err
+	}
+	if m.endpointDisagreementRatioGauge, err = meter.Float64ObservableGauge(
+		"ipni/lookout/endpoint_disagreement_ratio",
+		instrument.WithUnit("%"),
+		instrument.WithDescription("The ratio of lookups for which two endpoints returned different providers as a number between 0 and 1."),
+		instrument.WithFloat64Callback(m.observeDisagreementRatio),
+	); err != nil {
+		return err
+	}
+	if m.probeTimeToFindableHistogram, err = meter.Int64Histogram(
+		"ipni/lookout/probe_time_to_findable",
+		instrument.WithUnit("ms"),
+		instrument.WithDescription("The elapsed time from publishing an advertisement until its multihashes are findable in milliseconds."),
+	); err != nil {
+		return err
+	}
+	if m.probeTimeToUnfindableHistogram, err = meter.Int64Histogram(
+		"ipni/lookout/probe_time_to_unfindable",
+		instrument.WithUnit("ms"),
+		instrument.WithDescription("The elapsed time from publishing a removal advertisement until its multihashes are no longer findable in milliseconds."),
+	); err != nil {
+		return err
+	}
+	if m.probeSuccessGauge, err = meter.Int64ObservableGauge(
+		"ipni/lookout/probe_success",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("Whether the last run of each probe succeeded as 1, or failed as 0."),
+		instrument.WithInt64Callback(m.observeProbeSuccess),
+	); err != nil {
+		return err
+	}
+	if m.providerLastAdTimeGauge, err = meter.Int64ObservableGauge(
+		"ipni/lookout/provider_last_advertisement_time",
+		instrument.WithUnit("s"),
+		instrument.WithDescription("The time at which the last advertisement of each provider was processed by the endpoint in seconds since the Unix epoch."),
+		instrument.WithInt64Callback(m.observeProviderLastAdTime),
+	); err != nil {
+		return err
+	}
+	if m.providerLastAdAgeGauge, err = meter.Float64ObservableGauge(
+		"ipni/lookout/provider_last_advertisement_age",
+		instrument.WithUnit("s"),
+		instrument.WithDescription("The time elapsed since the last advertisement of each provider was processed by the endpoint in seconds."),
+		instrument.WithFloat64Callback(m.observeProviderLastAdAge),
+	); err != nil {
+		return err
+	}
+	if m.providerAdLagGauge, err = meter.Int64ObservableGauge(
+		"ipni/lookout/provider_advertisement_lag",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("The number of advertisements the endpoint is behind the publisher head of each provider."),
+		instrument.WithInt64Callback(m.observeProviderAdLag),
+	); err != nil {
+		return err
+	}
+	if m.providerFrozenGauge, err = meter.Int64ObservableGauge(
+		"ipni/lookout/provider_frozen",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("Whether the endpoint has frozen ingestion of each provider as 1, or not as 0."),
+		instrument.WithInt64Callback(m.observeProviderFrozen),
+	); err != nil {
+		return err
+	}
+	if m.providerErrorGauge, err = meter.Int64ObservableGauge(
+		"ipni/lookout/provider_error",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("Whether the endpoint recorded an ingestion error for each provider as 1, or not as 0."),
+		instrument.WithInt64Callback(m.observeProviderError),
+	); err != nil {
+		return err
+	}
+	if m.providerPublisherReachableGauge, err = meter.Int64ObservableGauge(
+		"ipni/lookout/provider_publisher_reachable",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("Whether the publisher of each provider is reachable as 1, or not as 0."),
+		instrument.WithInt64Callback(m.observeProviderPublisherReachable),
+	); err != nil {
+		return err
+	}
+	if m.providerInfoAvailableGauge, err = meter.Int64ObservableGauge(
+		"ipni/lookout/provider_info_available",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("Whether the endpoint returned the info of each provider as 1, or the lookup failed as 0."),
+		instrument.WithInt64Callback(m.observeProviderInfoAvailable),
+	); err != nil {
+		return err
+	}
+	if m.endpointHealthyGauge, err = meter.Int64ObservableGauge(
+		"ipni/lookout/endpoint_healthy",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("Whether the endpoint health API responded successfully as 1, or not as 0."),
+		instrument.WithInt64Callback(m.observeEndpointHealthy),
+	); err != nil {
+		return err
+	}
+	if m.endpointEntriesEstimateGauge, err = meter.Int64ObservableGauge(
+		"ipni/lookout/endpoint_entries_estimate",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("The estimated number of multihash entries indexed by the endpoint, as reported by its stats API."),
+		instrument.WithInt64Callback(m.observeEndpointEntriesEstimate),
+	); err != nil {
+		return err
+	}
+	if m.endpointEntriesCountGauge, err = meter.Int64ObservableGauge(
+		"ipni/lookout/endpoint_entries_count",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("The number of multihash entries indexed by the endpoint, as reported by its stats API."),
+		instrument.WithInt64Callback(m.observeEndpointEntriesCount),
+	); err != nil {
+		return err
+	}
+	if m.endpointProviderCountGauge, err = meter.Int64ObservableGauge(
+		"ipni/lookout/endpoint_provider_count",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("The number of providers known to the endpoint."),
+		instrument.WithInt64Callback(m.observeEndpointProviderCount),
+	); err != nil {
+		return err
+	}
+	if m.endpointHealthLatencyGauge, err = meter.Int64ObservableGauge(
+		"ipni/lookout/endpoint_health_latency",
+		instrument.WithUnit("ms"),
+		instrument.WithDescription("The time it took for the endpoint health API to respond in milliseconds."),
+		instrument.WithInt64Callback(m.observeEndpointHealthLatency),
+	); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -78,6 +275,15 @@ func (m *Metrics) observeSampleSetSize(_ context.Context, observer instrument.In
 	return nil
 }
 
+func (m *Metrics) observeSampleSetAge(_ context.Context, observer instrument.Float64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for sampler, sampledAt := range m.sampleSetSampledAt {
+		observer.Observe(time.Since(sampledAt).Seconds(), attribute.String("sampler", sampler))
+	}
+	return nil
+}
+
 func (m *Metrics) observeLookupSuccessRatio(_ context.Context, observer instrument.Float64Observer) error {
 	m.observablesLock.RLock()
 	defer m.observablesLock.RUnlock()
@@ -87,21 +293,195 @@ func (m *Metrics) observeLookupSuccessRatio(_ context.Context, observer instrume
 	return nil
 }
 
+func (m *Metrics) observeRetrievabilityRatio(_ context.Context, observer instrument.Float64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for attrs, ratio := range m.retrievabilityRatios {
+		observer.Observe(ratio, attrs.ToSlice()...)
+	}
+	return nil
+}
+
+func (m *Metrics) observeDisagreementRatio(_ context.Context, observer instrument.Float64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for attrs, ratio := range m.disagreementRatios {
+		observer.Observe(ratio, attrs.ToSlice()...)
+	}
+	return nil
+}
+
+func (m *Metrics) observeProbeSuccess(_ context.Context, observer instrument.Int64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for attrs, success := range m.probeSuccesses {
+		observer.Observe(success, attrs.ToSlice()...)
+	}
+	return nil
+}
+
+func (m *Metrics) observeProviderLastAdTime(_ context.Context, observer instrument.Int64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for attrs, h := range m.providerHealths {
+		if !h.LastAdvertisementTime.IsZero() {
+			observer.Observe(h.LastAdvertisementTime.Unix(), attrs.ToSlice()...)
+		}
+	}
+	return nil
+}
+
+func (m *Metrics) observeProviderLastAdAge(_ context.Context, observer instrument.Float64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for attrs, h := range m.providerHealths {
+		if !h.LastAdvertisementTime.IsZero() {
+			observer.Observe(time.Since(h.LastAdvertisementTime).Seconds(), attrs.ToSlice()...)
+		}
+	}
+	return nil
+}
+
+func (m *Metrics) observeProviderAdLag(_ context.Context, observer instrument.Int64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for attrs, h := range m.providerHealths {
+		if h.Err == nil {
+			observer.Observe(int64(h.Lag), attrs.ToSlice()...)
+		}
+	}
+	return nil
+}
+
+func (m *Metrics) observeProviderFrozen(_ context.Context, observer instrument.Int64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for attrs, h := range m.providerHealths {
+		if h.Err == nil {
+			observer.Observe(boolToInt64(h.Frozen), attrs.ToSlice()...)
+		}
+	}
+	return nil
+}
+
+func (m *Metrics) observeProviderError(_ context.Context, observer instrument.Int64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for attrs, h := range m.providerHealths {
+		if h.Err == nil {
+			observer.Observe(boolToInt64(h.HasError()), attrs.ToSlice()...)
+		}
+	}
+	return nil
+}
+
+func (m *Metrics) observeProviderPublisherReachable(_ context.Context, observer instrument.Int64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for attrs, h := range m.providerHealths {
+		if h.Err == nil {
+			observer.Observe(boolToInt64(h.PublisherReachable), attrs.ToSlice()...)
+		}
+	}
+	return nil
+}
+
+func (m *Metrics) observeProviderInfoAvailable(_ context.Context, observer instrument.Int64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for attrs, h := range m.providerHealths {
+		observer.Observe(boolToInt64(h.Err == nil), attrs.ToSlice()...)
+	}
+	return nil
+}
+
+func (m *Metrics) observeEndpointHealthy(_ context.Context, observer instrument.Int64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for checker, r := range m.indexerStatuses {
+		observer.Observe(boolToInt64(r.Healthy), attribute.String("checker", checker))
+	}
+	return nil
+}
+
+func (m *Metrics) observeEndpointEntriesEstimate(_ context.Context, observer instrument.Int64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for checker, r := range m.indexerStatuses {
+		if r.StatsErr == nil {
+			observer.Observe(r.EntriesEstimate, attribute.String("checker", checker))
+		}
+	}
+	return nil
+}
+
+func (m *Metrics) observeEndpointEntriesCount(_ context.Context, observer instrument.Int64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for checker, r := range m.indexerStatuses {
+		if r.StatsErr == nil {
+			observer.Observe(r.EntriesCount, attribute.String("checker", checker))
+		}
+	}
+	return nil
+}
+
+func (m *Metrics) observeEndpointProviderCount(_ context.Context, observer instrument.Int64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for checker, r := range m.indexerStatuses {
+		if r.ProvidersCounted && r.ProvidersErr == nil {
+			observer.Observe(int64(r.ProviderCount), attribute.String("checker", checker))
+		}
+	}
+	return nil
+}
+
+func (m *Metrics) observeEndpointHealthLatency(_ context.Context, observer instrument.Int64Observer) error {
+	m.observablesLock.RLock()
+	defer m.observablesLock.RUnlock()
+	for checker, r := range m.indexerStatuses {
+		if r.HealthErr == nil {
+			observer.Observe(r.HealthElapsed.Milliseconds(), attribute.String("checker", checker))
+		}
+	}
+	return nil
+}
+
 func (m *Metrics) NotifySampleSet(_ context.Context, ss *sample.Set) {
 	m.observablesLock.Lock()
 	defer m.observablesLock.Unlock()
 	m.sampleSetSizes[ss.Name] = int64(len(ss.Cids))
+	sampledAt := ss.SampledAt
+	if sampledAt.IsZero() {
+		sampledAt = time.Now()
+	}
+	m.sampleSetSampledAt[ss.Name] = sampledAt
 }
 
 func (m *Metrics) NotifyCheckResults(ctx context.Context, results *check.Results) {
 	checkerAttr := attribute.String("checker", results.CheckerName)
 	sampleAttr := attribute.String("sampler", results.SampleSetName)
-	var success int
+	type tally struct{ success, total int }
+	tallies := make(map[check.LookupPath]*tally)
+	type retrievalKey struct{ provider, protocol string }
+	retrievalTallies := make(map[retrievalKey]*tally)
+	type endpointsKey struct{ a, b string }
+	type disagreementTally struct{ disagreements, total int }
+	disagreementTallies := make(map[endpointsKey]*disagreementTally)
 	for _, result := range results.Results {
-		if result.StatusCode == http.StatusOK {
-			success++
+		t, ok := tallies[result.LookupPath]
+		if !ok {
+			t = &tally{}
+			tallies[result.LookupPath] = t
+		}
+		t.total++
+		if result.Succeeded() {
+			t.success++
 		}
-		// TODO check error for context timeout or cancellation
+		pathAttr := lookupPathAttr(result.LookupPath)
+		reason := result.FailureReason()
+		reasonAttr := attribute.String("failure_reason", string(reason))
 		m.checkLatencyHistogram.Record(
 			ctx,
 			result.Elapsed.Milliseconds(),
@@ -111,17 +491,157 @@ func (m *Metrics) NotifyCheckResults(ctx context.Context, results *check.Results
 			attribute.Bool("error", result.Err != nil),
 			attribute.String("timeout", result.Timeout.String()),
 			attribute.Bool("streaming", result.Streaming),
+			pathAttr,
+			reasonAttr,
 		)
-	}
-	var ratio float64
-	if total := len(results.Results); total > 0 {
-		ratio = float64(success) / float64(total)
+		if reason != check.FailureReasonNone {
+			m.checkFailuresCounter.Add(ctx, 1, checkerAttr, sampleAttr, pathAttr, reasonAttr)
+		}
+		if result.Streaming && result.TimeToFirstProvider > 0 {
+			m.checkTimeToFirstProviderHistogram.Record(
+				ctx,
+				result.TimeToFirstProvider.Milliseconds(),
+				checkerAttr,
+				sampleAttr,
+				attribute.String("timeout", result.Timeout.String()),
+				pathAttr,
+			)
+		}
+		for protocol, count := range result.ProvidersByProtocol {
+			m.checkProviderCountHistogram.Record(
+				ctx,
+				int64(count),
+				checkerAttr,
+				sampleAttr,
+				pathAttr,
+				attribute.String("protocol", protocol),
+			)
+		}
+		for provider, retrievals := range result.Retrievals {
+			for _, retrieval := range retrievals {
+				key := retrievalKey{provider: provider, protocol: retrieval.Protocol}
+				rt, ok := retrievalTallies[key]
+				if !ok {
+					rt = &tally{}
+					retrievalTallies[key] = rt
+				}
+				rt.total++
+				if retrieval.Retrievable {
+					rt.success++
+				}
+			}
+		}
+		for _, cmp := range result.Comparisons {
+			key := endpointsKey{a: cmp.EndpointA, b: cmp.EndpointB}
+			dt, ok := disagreementTallies[key]
+			if !ok {
+				dt = &disagreementTally{}
+				disagreementTallies[key] = dt
+			}
+			dt.total++
+			if !cmp.Agrees() {
+				dt.disagreements++
+			}
+		}
 	}
 	// Store ratio even if it is zero so that it can be used for alerting.
 	// If it is zero, the chances are something is not right.
 	m.observablesLock.Lock()
 	defer m.observablesLock.Unlock()
-	m.lookupSuccessRatios[attribute.NewSet(checkerAttr, sampleAttr)] = ratio
+	if len(tallies) == 0 {
+		paths := results.LookupPaths
+		if len(paths) == 0 {
+			paths = []check.LookupPath{check.LookupPathCid}
+		}
+		for _, path := range paths {
+			m.lookupSuccessRatios[attribute.NewSet(checkerAttr, sampleAttr, lookupPathAttr(path))] = 0
+		}
+	}
+	for path, t := range tallies {
+		m.lookupSuccessRatios[attribute.NewSet(checkerAttr, sampleAttr, lookupPathAttr(path))] = float64(t.success) / float64(t.total)
+	}
+	if len(retrievalTallies) != 0 {
+		// Replace the ratios from previous cycle so that providers no longer returned are dropped.
+		for attrs := range m.retrievabilityRatios {
+			checker, _ := attrs.Value(checkerAttr.Key)
+			sampler, _ := attrs.Value(sampleAttr.Key)
+			if checker == checkerAttr.Value && sampler == sampleAttr.Value {
+				delete(m.retrievabilityRatios, attrs)
+			}
+		}
+		for key, rt := range retrievalTallies {
+			attrs := attribute.NewSet(checkerAttr, sampleAttr,
+				attribute.String("provider", key.provider),
+				attribute.String("protocol", key.protocol))
+			m.retrievabilityRatios[attrs] = float64(rt.success) / float64(rt.total)
+		}
+	}
+	for key, dt := range disagreementTallies {
+		attrs := attribute.NewSet(checkerAttr, sampleAttr,
+			attribute.String("endpoint_a", key.a),
+			attribute.String("endpoint_b", key.b))
+		m.disagreementRatios[attrs] = float64(dt.disagreements) / float64(dt.total)
+	}
+}
+
+func (m *Metrics) NotifyProbeResult(ctx context.Context, r *probe.Result) {
+	probeAttr := attribute.String("probe", r.ProbeName)
+	kindAttr := attribute.String("kind", string(r.Kind))
+	if r.Succeeded {
+		switch r.Kind {
+		case probe.KindFindable:
+			m.probeTimeToFindableHistogram.Record(ctx, r.Elapsed.Milliseconds(), probeAttr)
+		case probe.KindUnfindable:
+			m.probeTimeToUnfindableHistogram.Record(ctx, r.Elapsed.Milliseconds(), probeAttr)
+		}
+	}
+	m.observablesLock.Lock()
+	defer m.observablesLock.Unlock()
+	m.probeSuccesses[attribute.NewSet(probeAttr, kindAttr)] = boolToInt64(r.Succeeded)
+}
+
+func (m *Metrics) NotifyEndpointResults(_ context.Context, results check.EndpointResults) {
+	checkerAttr := attribute.String("checker", results.Checker())
+	switch r := results.(type) {
+	case *check.ProviderHealthResults:
+		if r.Err != nil {
+			// Keep the healths from previous cycle, since the providers could not be listed.
+			return
+		}
+		m.observablesLock.Lock()
+		defer m.observablesLock.Unlock()
+		// Replace the healths from previous cycle so that providers no longer listed are dropped.
+		for attrs := range m.providerHealths {
+			if checker, _ := attrs.Value(checkerAttr.Key); checker == checkerAttr.Value {
+				delete(m.providerHealths, attrs)
+			}
+		}
+		// Providers whose info lookup failed are kept so that the failure is reported, but only
+		// via provider_info_available since the rest of their health is unknown.
+		for _, h := range r.Providers {
+			m.providerHealths[attribute.NewSet(checkerAttr, attribute.String("provider", h.ID))] = h
+		}
+	case *check.IndexerStatusResults:
+		m.observablesLock.Lock()
+		defer m.observablesLock.Unlock()
+		m.indexerStatuses[r.CheckerName] = r
+	}
+}
+
+// lookupPathAttr returns the metric attribute for the given lookup path, defaulting to
+// check.LookupPathCid so that the attribute value is never empty.
+func lookupPathAttr(lp check.LookupPath) attribute.KeyValue {
+	if lp == "" {
+		lp = check.LookupPathCid
+	}
+	return attribute.String("path", string(lp))
+}
+
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
 }
 
 func (m *Metrics) Shutdown(ctx context.Context) error {
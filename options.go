@@ -1,10 +1,13 @@
 package lookout
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/ipni/lookout/check"
+	"github.com/ipni/lookout/probe"
 	"github.com/ipni/lookout/sample"
+	"github.com/ipni/lookout/sink"
 )
 
 type (
@@ -15,7 +18,17 @@ type (
 		checkersParallelism int
 		samplersParallelism int
 		checkers            []check.Checker
+		endpointCheckers    []check.EndpointChecker
 		samplers            []sample.Sampler
+		maxPendingCycles    int
+		maxCheckCids        int
+		resultsSink         sink.Sink
+		snapshotDir         string
+		snapshotMaxCount    int
+		snapshotMaxAge      time.Duration
+		publisher           *probe.Publisher
+		probes              []probe.Probe
+		probeInterval       time.Duration
 	}
 )
 
@@ -25,6 +38,10 @@ func newOptions(o ...Option) (*options, error) {
 		checkInterval:       time.NewTicker(5 * time.Minute),
 		checkersParallelism: 10,
 		samplersParallelism: 10,
+		maxPendingCycles:    10,
+		maxCheckCids:        100,
+		snapshotMaxCount:    100,
+		probeInterval:       10 * time.Minute,
 	}
 	for _, apply := range o {
 		if err := apply(&opts); err != nil {
@@ -49,6 +66,15 @@ func WithCheckers(c ...check.Checker) Option {
 	}
 }
 
+// WithEndpointCheckers sets the checkers that check IPNI endpoints as a whole once per cycle,
+// independently of sample sets.
+func WithEndpointCheckers(c ...check.EndpointChecker) Option {
+	return func(o *options) error {
+		o.endpointCheckers = c
+		return nil
+	}
+}
+
 func WithSamplers(s ...sample.Sampler) Option {
 	return func(o *options) error {
 		o.samplers = s
@@ -76,3 +102,101 @@ func WithSamplersParallelism(p int) Option {
 		return nil
 	}
 }
+
+// WithMaxPendingCycles sets the maximum number of ad-hoc cycles that can be queued to run once the
+// current cycle is finished. Further requests are rejected until pending cycles are started.
+// Defaults to 10.
+func WithMaxPendingCycles(m int) Option {
+	return func(o *options) error {
+		if m < 0 {
+			return fmt.Errorf("max pending cycles cannot be negative; got %d", m)
+		}
+		o.maxPendingCycles = m
+		return nil
+	}
+}
+
+// WithMaxCheckCids sets the maximum number of CIDs that can be checked by a single ad-hoc check
+// request. Requests with more CIDs are rejected. Defaults to 100.
+func WithMaxCheckCids(m int) Option {
+	return func(o *options) error {
+		if m < 1 {
+			return fmt.Errorf("max check CIDs must be at least 1; got %d", m)
+		}
+		o.maxCheckCids = m
+		return nil
+	}
+}
+
+// WithResultsSink sets the sink to which every check result is recorded, along with the cycle ID,
+// sampler name, checker name and timestamp. Defaults to no sink.
+func WithResultsSink(s sink.Sink) Option {
+	return func(o *options) error {
+		o.resultsSink = s
+		return nil
+	}
+}
+
+// WithSnapshotDir sets the directory to which every sample set is written as a snapshot, so that
+// cycles can be replayed against the same CIDs. Defaults to no snapshots.
+func WithSnapshotDir(dir string) Option {
+	return func(o *options) error {
+		o.snapshotDir = dir
+		return nil
+	}
+}
+
+// WithSnapshotMaxCount sets the maximum number of snapshots kept per sample set. The oldest
+// snapshots beyond the maximum are removed after every snapshot is written. Zero keeps all
+// snapshots. Defaults to 100.
+func WithSnapshotMaxCount(m int) Option {
+	return func(o *options) error {
+		if m < 0 {
+			return fmt.Errorf("snapshot max count cannot be negative; got %d", m)
+		}
+		o.snapshotMaxCount = m
+		return nil
+	}
+}
+
+// WithSnapshotMaxAge sets the maximum age of snapshots kept per sample set. Older snapshots are
+// removed after every snapshot is written. Zero keeps snapshots regardless of age, which is the
+// default.
+func WithSnapshotMaxAge(a time.Duration) Option {
+	return func(o *options) error {
+		if a < 0 {
+			return fmt.Errorf("snapshot max age cannot be negative; got %s", a)
+		}
+		o.snapshotMaxAge = a
+		return nil
+	}
+}
+
+// WithPublisher sets the embedded publisher through which probes publish advertisements. The
+// publisher is started and shut down along with lookout. Defaults to no publisher.
+func WithPublisher(p *probe.Publisher) Option {
+	return func(o *options) error {
+		o.publisher = p
+		return nil
+	}
+}
+
+// WithProbes sets the probes to run, each independently of the others and of check cycles.
+func WithProbes(p ...probe.Probe) Option {
+	return func(o *options) error {
+		o.probes = p
+		return nil
+	}
+}
+
+// WithProbeInterval sets the interval at which each probe is run. A probe run that takes longer
+// than the interval delays the next run. Defaults to 10 minutes.
+func WithProbeInterval(i time.Duration) Option {
+	return func(o *options) error {
+		if i <= 0 {
+			return fmt.Errorf("probe interval must be positive; got %s", i)
+		}
+		o.probeInterval = i
+		return nil
+	}
+}
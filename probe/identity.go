@@ -0,0 +1,154 @@
+package probe
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mr-tron/base58"
+	"github.com/multiformats/go-multihash"
+)
+
+const (
+	// keyTypeEd25519 is the libp2p protobuf key type of Ed25519 keys.
+	keyTypeEd25519 = 1
+
+	// adSignatureDomain and adSignatureCodec are the domain and payload type of the signed
+	// envelopes that carry advertisement signatures.
+	adSignatureDomain = "indexer"
+	adSignatureCodec  = "/indexer/ingest/adSignature"
+)
+
+// identity is the Ed25519 key pair that identifies the publisher as a libp2p peer.
+type identity struct {
+	key ed25519.PrivateKey
+	// peerID is the base58 encoded libp2p peer ID derived from the public key.
+	peerID string
+}
+
+// loadOrGenerateIdentity loads the private key stored at the given path in libp2p protobuf format,
+// or generates one and stores it there if the file does not exist. When the path is empty, an
+// ephemeral key is generated.
+func loadOrGenerateIdentity(path string) (*identity, error) {
+	if path == "" {
+		return generateIdentity()
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		id, err := generateIdentity()
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Clean(path), marshalKey(id.key), 0o600); err != nil {
+			return nil, err
+		}
+		logger.Infow("Generated publisher identity", "path", path, "peerID", id.peerID)
+		return id, nil
+	case err != nil:
+		return nil, err
+	}
+	keyType, keyData, err := unmarshalKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode identity at %s: %w", path, err)
+	}
+	if keyType != keyTypeEd25519 || len(keyData) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("identity at %s is not an Ed25519 private key", path)
+	}
+	return newIdentity(ed25519.PrivateKey(keyData))
+}
+
+func generateIdentity() (*identity, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return newIdentity(key)
+}
+
+func newIdentity(key ed25519.PrivateKey) (*identity, error) {
+	// Ed25519 public keys are small enough to be inlined in peer IDs as identity multihashes.
+	mh, err := multihash.Sum(marshalKey(key.Public().(ed25519.PublicKey)), multihash.IDENTITY, -1)
+	if err != nil {
+		return nil, err
+	}
+	return &identity{key: key, peerID: base58.Encode(mh)}, nil
+}
+
+// marshalPublicKey returns the public key in libp2p protobuf format.
+func (i *identity) marshalPublicKey() []byte {
+	return marshalKey(i.key.Public().(ed25519.PublicKey))
+}
+
+// sign signs the given data.
+func (i *identity) sign(data []byte) []byte {
+	return ed25519.Sign(i.key, data)
+}
+
+// sealEnvelope signs the given payload and wraps it in a libp2p signed envelope, marshalled in
+// protobuf format.
+func (i *identity) sealEnvelope(domain, payloadType string, payload []byte) []byte {
+	// The signature covers the length-prefixed domain, payload type and payload.
+	var unsigned []byte
+	for _, field := range [][]byte{[]byte(domain), []byte(payloadType), payload} {
+		unsigned = binary.AppendUvarint(unsigned, uint64(len(field)))
+		unsigned = append(unsigned, field...)
+	}
+	var envelope bytes.Buffer
+	writeProtoBytes(&envelope, 1, i.marshalPublicKey())
+	writeProtoBytes(&envelope, 2, []byte(payloadType))
+	writeProtoBytes(&envelope, 3, payload)
+	writeProtoBytes(&envelope, 5, i.sign(unsigned))
+	return envelope.Bytes()
+}
+
+// marshalKey marshals the given Ed25519 key in libp2p protobuf format, i.e. a message with key
+// type as field 1 and key data as field 2.
+func marshalKey(key []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(1 << 3) // Field 1, varint wire type.
+	buf.Write(binary.AppendUvarint(nil, keyTypeEd25519))
+	writeProtoBytes(&buf, 2, key)
+	return buf.Bytes()
+}
+
+func unmarshalKey(data []byte) (uint64, []byte, error) {
+	var keyType uint64
+	var keyData []byte
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, nil, errors.New("invalid protobuf tag")
+		}
+		data = data[n:]
+		switch tag {
+		case 1 << 3:
+			if keyType, n = binary.Uvarint(data); n <= 0 {
+				return 0, nil, errors.New("invalid key type")
+			}
+			data = data[n:]
+		case 2<<3 | 2:
+			l, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < l {
+				return 0, nil, errors.New("invalid key data")
+			}
+			keyData = data[n : n+int(l)]
+			data = data[n+int(l):]
+		default:
+			return 0, nil, fmt.Errorf("unexpected protobuf tag: %d", tag)
+		}
+	}
+	return keyType, keyData, nil
+}
+
+// writeProtoBytes writes the given value as a length-delimited protobuf field.
+func writeProtoBytes(buf *bytes.Buffer, field int, value []byte) {
+	buf.Write(binary.AppendUvarint(nil, uint64(field<<3|2)))
+	buf.Write(binary.AppendUvarint(nil, uint64(len(value))))
+	buf.Write(value)
+}
@@ -0,0 +1,99 @@
+package probe
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The vectors below use the Ed25519 key from the libp2p peer ID spec, and are computed
+// independently of this package: signatures by the RFC 8032 reference implementation, and
+// protobuf messages according to the libp2p key and signed envelope specs.
+const (
+	testPrivateKey = "080112407e0830617c4a7de83925dfb2694556b12936c477a0e1feb2e148ec9da60fee7d1ed1e8fae2c4a144b8be8fd4b47bf3d3b34b871c3cacf6010f0e42d474fce27e"
+	testPublicKey  = "080112201ed1e8fae2c4a144b8be8fd4b47bf3d3b34b871c3cacf6010f0e42d474fce27e"
+	testPeerID     = "12D3KooWBtg3aaRMjxwedh83aGiUkwSxDwUZkzuJcfaqUmo7R3pq"
+	// testEnvelope is the envelope sealing payload "fish" in the advertisement signature domain.
+	testEnvelope = "0a24080112201ed1e8fae2c4a144b8be8fd4b47bf3d3b34b871c3cacf6010f0e42d474fce27e121b2f696e64657865722f696e676573742f61645369676e61747572651a04666973682a4021bfab7124dfca455fc171408b050d3d04738ac022e0b192f14c26ed0933f912bfab978dce1f201a7fad72300d59f8a72a0c6728b6e87d4da1b91e2b0749a30a"
+)
+
+func TestLoadOrGenerateIdentity_Existing(t *testing.T) {
+	id := testIdentity(t)
+	if id.peerID != testPeerID {
+		t.Fatalf("expected peer ID %s; got %s", testPeerID, id.peerID)
+	}
+	if got := hex.EncodeToString(id.marshalPublicKey()); got != testPublicKey {
+		t.Fatalf("expected public key %s; got %s", testPublicKey, got)
+	}
+	if got := hex.EncodeToString(marshalKey(id.key)); got != testPrivateKey {
+		t.Fatalf("expected private key %s; got %s", testPrivateKey, got)
+	}
+}
+
+func TestLoadOrGenerateIdentity_Generated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "identity.key")
+	generated, err := loadOrGenerateIdentity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadOrGenerateIdentity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.peerID != generated.peerID || !bytes.Equal(loaded.key, generated.key) {
+		t.Fatalf("expected stored identity %s to be loaded; got %s", generated.peerID, loaded.peerID)
+	}
+}
+
+func TestLoadOrGenerateIdentity_NotEd25519(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "identity.key")
+	// An RSA key type with arbitrary key data.
+	if err := os.WriteFile(path, []byte{0x08, 0x00, 0x12, 0x01, 0x00}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadOrGenerateIdentity(path); err == nil {
+		t.Fatal("expected non-Ed25519 identity to be rejected")
+	}
+}
+
+func TestSealEnvelope(t *testing.T) {
+	id := testIdentity(t)
+	envelope := id.sealEnvelope(adSignatureDomain, adSignatureCodec, []byte("fish"))
+	if got := hex.EncodeToString(envelope); got != testEnvelope {
+		t.Fatalf("expected envelope %s; got %s", testEnvelope, got)
+	}
+}
+
+func TestSign_RFC8032(t *testing.T) {
+	// Test 1 of RFC 8032 section 7.1.
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	id, err := newIdentity(ed25519.NewKeyFromSeed(seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b"
+	if got := hex.EncodeToString(id.sign(nil)); got != want {
+		t.Fatalf("expected signature %s; got %s", want, got)
+	}
+}
+
+// testIdentity loads the test identity from a file, the same way as publishers do.
+func testIdentity(t *testing.T) *identity {
+	t.Helper()
+	key, err := hex.DecodeString(testPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "identity.key")
+	if err := os.WriteFile(path, key, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	id, err := loadOrGenerateIdentity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
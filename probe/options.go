@@ -0,0 +1,189 @@
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ipni/lookout/check"
+	"github.com/ipni/lookout/internal/maddr"
+)
+
+type (
+	Option  func(*options) error
+	options struct {
+		name           string
+		httpClient     *http.Client
+		listenAddr     string
+		publisherAddrs []string
+		providerAddrs  []string
+		announceUrls   []string
+		identityPath   string
+		topic          string
+		publisher      *Publisher
+		checker        check.Checker
+		entries        int
+		pollInterval   time.Duration
+		probeTimeout   time.Duration
+	}
+)
+
+func newOptions(o ...Option) (*options, error) {
+	opts := options{
+		httpClient:   http.DefaultClient,
+		listenAddr:   "0.0.0.0:40081",
+		topic:        "/indexer/ingest/mainnet",
+		entries:      10,
+		pollInterval: 10 * time.Second,
+		probeTimeout: 10 * time.Minute,
+	}
+	for _, apply := range o {
+		if err := apply(&opts); err != nil {
+			return nil, err
+		}
+	}
+	return &opts, nil
+}
+
+// Name returns the name of the probe.
+func (o *options) Name() string {
+	return o.name
+}
+
+func WithName(n string) Option {
+	return func(o *options) error {
+		if n == "" {
+			return errors.New("name cannot be empty")
+		}
+		o.name = n
+		return nil
+	}
+}
+
+// WithHttpClient sets the HTTP client used to announce advertisements. Defaults to
+// http.DefaultClient.
+func WithHttpClient(c *http.Client) Option {
+	return func(o *options) error {
+		o.httpClient = c
+		return nil
+	}
+}
+
+// WithListenAddr sets the address on which the publisher serves advertisements over HTTP.
+// Defaults to 0.0.0.0:40081.
+func WithListenAddr(a string) Option {
+	return func(o *options) error {
+		o.listenAddr = a
+		return nil
+	}
+}
+
+// WithPublisherAddrs sets the HTTP multiaddrs at which the publisher is reachable by indexers,
+// e.g. /dns4/lookout.example.com/tcp/40081/http. At least one must be specified.
+func WithPublisherAddrs(a ...string) Option {
+	return func(o *options) error {
+		for _, addr := range a {
+			ma, err := maddr.Parse(addr)
+			if err != nil {
+				return err
+			}
+			if !ma.IsHTTP() {
+				return fmt.Errorf("publisher address must be an HTTP multiaddr: %s", addr)
+			}
+		}
+		o.publisherAddrs = a
+		return nil
+	}
+}
+
+// WithProviderAddrs sets the multiaddrs advertised as the addresses of the provider. Defaults to
+// the publisher addresses.
+func WithProviderAddrs(a ...string) Option {
+	return func(o *options) error {
+		o.providerAddrs = a
+		return nil
+	}
+}
+
+// WithAnnounceUrls sets the URLs to which new advertisements are announced via HTTP PUT, e.g. the
+// announce endpoint of an indexer.
+func WithAnnounceUrls(u ...string) Option {
+	return func(o *options) error {
+		o.announceUrls = u
+		return nil
+	}
+}
+
+// WithIdentityPath sets the path to the file storing the private key of the publisher, which
+// identifies it as a provider. The key is generated and written to the file if it does not exist.
+// When unset, an ephemeral key is generated every time the publisher is instantiated.
+func WithIdentityPath(p string) Option {
+	return func(o *options) error {
+		o.identityPath = p
+		return nil
+	}
+}
+
+// WithTopic sets the topic on which the publisher signs its head advertisement.
+// Defaults to /indexer/ingest/mainnet.
+func WithTopic(t string) Option {
+	return func(o *options) error {
+		o.topic = t
+		return nil
+	}
+}
+
+// WithPublisher sets the publisher through which a probe publishes advertisements.
+func WithPublisher(p *Publisher) Option {
+	return func(o *options) error {
+		o.publisher = p
+		return nil
+	}
+}
+
+// WithChecker sets the checker with which a probe looks up the multihashes it published. The
+// checker must report the IDs of the providers found, e.g. check.IpniNonStreamingChecker.
+func WithChecker(c check.Checker) Option {
+	return func(o *options) error {
+		o.checker = c
+		return nil
+	}
+}
+
+// WithEntries sets the number of random multihashes published per probe. Defaults to 10.
+func WithEntries(e int) Option {
+	return func(o *options) error {
+		if e < 1 {
+			return fmt.Errorf("entries cannot be less than 1; got %d", e)
+		}
+		o.entries = e
+		return nil
+	}
+}
+
+// WithPollInterval sets the interval at which a probe looks up the multihashes it published.
+// Defaults to 10 seconds.
+func WithPollInterval(i time.Duration) Option {
+	return func(o *options) error {
+		if i <= 0 {
+			return fmt.Errorf("poll interval must be positive; got %s", i)
+		}
+		o.pollInterval = i
+		return nil
+	}
+}
+
+// WithProbeTimeout sets the maximum time a probe waits for the multihashes it published to
+// become findable, or the multihashes it removed to become unfindable, after which the probe
+// fails. For removal probes, this is the SLA within which removals must take effect.
+// Defaults to 10 minutes.
+func WithProbeTimeout(t time.Duration) Option {
+	return func(o *options) error {
+		if t <= 0 {
+			return fmt.Errorf("probe timeout must be positive; got %s", t)
+		}
+		o.probeTimeout = t
+		return nil
+	}
+}
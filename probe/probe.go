@@ -0,0 +1,46 @@
+package probe
+
+import (
+	"context"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-log/v2"
+	"github.com/multiformats/go-multihash"
+)
+
+var logger = log.Logger("ipni/lookout/probe")
+
+const (
+	// KindFindable is the kind of probes that measure the time it takes for published multihashes
+	// to become findable.
+	KindFindable Kind = "findable"
+	// KindUnfindable is the kind of probes that measure the time it takes for removed multihashes
+	// to become unfindable.
+	KindUnfindable Kind = "unfindable"
+)
+
+type (
+	// Kind represents what a probe measures.
+	Kind string
+
+	// Probe actively exercises an IPNI endpoint, e.g. by publishing advertisements to it, as
+	// opposed to a check.Checker that passively looks up sampled CIDs.
+	Probe interface {
+		Probe(context.Context) *Result
+	}
+	Result struct {
+		ProbeName string
+		Kind      Kind
+		// Provider is the ID of the provider on behalf of which the probe published.
+		Provider string
+		// Advertisement is the CID of the advertisement published by the probe, if any.
+		Advertisement cid.Cid
+		Multihashes   []multihash.Multihash
+		// Elapsed is the time it took from publishing the advertisement until the probe succeeded
+		// or failed.
+		Elapsed   time.Duration
+		Succeeded bool
+		Err       error
+	}
+)
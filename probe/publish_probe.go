@@ -0,0 +1,155 @@
+package probe
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipni/lookout/check"
+	"github.com/ipni/lookout/sample"
+	"github.com/multiformats/go-multihash"
+)
+
+var _ Probe = (*PublishProbe)(nil)
+
+// PublishProbe publishes an advertisement for random multihashes via the embedded publisher, then
+// looks them up via the configured checker until they are all found with the publisher as
+// provider, measuring the time it takes for published content to become findable.
+// The publication is released for removal once the probe completes.
+type PublishProbe struct {
+	*options
+}
+
+func NewPublishProbe(o ...Option) (*PublishProbe, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	if opts.name == "" {
+		return nil, errors.New("probe name must be specified")
+	}
+	if opts.publisher == nil {
+		return nil, errors.New("publisher must be specified")
+	}
+	if opts.checker == nil {
+		return nil, errors.New("checker must be specified")
+	}
+	return &PublishProbe{options: opts}, nil
+}
+
+func (p *PublishProbe) Probe(ctx context.Context) *Result {
+	result := &Result{
+		ProbeName: p.name,
+		Kind:      KindFindable,
+		Provider:  p.publisher.ID(),
+	}
+	contextID, mhs, err := randomContent(p.entries)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Multihashes = mhs
+
+	start := time.Now()
+	result.Advertisement, err = p.publisher.Publish(ctx, contextID, mhs)
+	if !result.Advertisement.Defined() {
+		result.Err = err
+		return result
+	}
+	if err != nil {
+		// The advertisement is published regardless; the indexer may still discover it.
+		logger.Warnw("Failed to announce advertisement", "probe", p.name, "ad", result.Advertisement, "err", err)
+	}
+	result.Err = p.pollUntilFindable(ctx, mhs)
+	result.Elapsed = time.Since(start)
+	result.Succeeded = result.Err == nil
+	// Only now that the lookups are no longer timed may a RemovalProbe take the publication.
+	p.publisher.Release(result.Advertisement)
+	return result
+}
+
+// pollUntilFindable looks up the given multihashes via the configured checker at the configured
+// poll interval, until they are all found with the publisher as provider. Returns an error if
+// they do not become findable within the probe timeout.
+func (o *options) pollUntilFindable(ctx context.Context, mhs []multihash.Multihash) error {
+	return o.pollUntil(ctx, mhs, "findable", o.foundPublisher)
+}
+
+// pollUntil looks up the given multihashes via the configured checker at the configured poll
+// interval, until the given condition holds for the lookup of every multihash. Returns an error
+// if it does not within the probe timeout.
+func (o *options) pollUntil(ctx context.Context, mhs []multihash.Multihash, state string, cond func(*check.Result) bool) error {
+	ctx, cancel := context.WithTimeout(ctx, o.probeTimeout)
+	defer cancel()
+	set := &sample.Set{Name: o.name, Provider: o.publisher.ID()}
+	for _, mh := range mhs {
+		set.Cids = append(set.Cids, cid.NewCidV1(cid.Raw, mh))
+	}
+	ticker := time.NewTicker(o.pollInterval)
+	defer ticker.Stop()
+	for {
+		results := o.checker.Check(ctx, set)
+		if ctx.Err() == nil && holdsForAll(results, len(set.Cids), cond) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("multihashes did not become %s within %s", state, o.probeTimeout)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// holdsForAll checks whether the given results cover all the expected lookups, and the given
+// condition holds for every one of them.
+func holdsForAll(results *check.Results, count int, cond func(*check.Result) bool) bool {
+	if results == nil || len(results.Results) < count {
+		return false
+	}
+	for _, r := range results.Results {
+		if !cond(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// foundPublisher checks whether the given lookup succeeded with the publisher as a provider.
+func (o *options) foundPublisher(r *check.Result) bool {
+	if !r.Succeeded() {
+		return false
+	}
+	for _, id := range r.PeerIDs {
+		if id == o.publisher.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+// randomContent generates a random context ID along with n random multihashes.
+func randomContent(n int) ([]byte, []multihash.Multihash, error) {
+	contextID := make([]byte, 16)
+	if _, err := rand.Read(contextID); err != nil {
+		return nil, nil, err
+	}
+	mhs := make([]multihash.Multihash, 0, n)
+	data := make([]byte, 32)
+	for i := 0; i < n; i++ {
+		if _, err := rand.Read(data); err != nil {
+			return nil, nil, err
+		}
+		mh, err := multihash.Sum(data, multihash.SHA2_256, -1)
+		if err != nil {
+			return nil, nil, err
+		}
+		mhs = append(mhs, mh)
+	}
+	return contextID, mhs, nil
+}
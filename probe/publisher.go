@@ -0,0 +1,326 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipni/lookout/internal/ad"
+	"github.com/ipni/lookout/internal/maddr"
+	"github.com/multiformats/go-multihash"
+)
+
+const (
+	// ipniSyncPath is the path under which IPNI HTTP publishers serve advertisements.
+	ipniSyncPath = "/ipni/v1/ad/"
+	// cidSchemaHeader is the header by which IPNI HTTP publishers hint at the type of served blocks.
+	cidSchemaHeader = "Ipni-Cid-Schema-Type"
+	// transportIpfsGatewayHttpCode is the multicodec code of trustless IPFS gateway transport,
+	// advertised as the metadata of published advertisements.
+	transportIpfsGatewayHttpCode = 0x0920
+	// maxPublications is the maximum number of publications tracked for removal, beyond which the
+	// oldest are no longer tracked.
+	maxPublications = 1000
+)
+
+type (
+	// Publisher is an embedded IPNI HTTP publisher that publishes advertisements on behalf of
+	// probes, acting as a provider. Advertisements and entry chunks are kept in memory for the
+	// lifetime of the publisher, and served at /ipni/v1/ad/ of the configured listen address.
+	Publisher struct {
+		*options
+		id     *identity
+		server *http.Server
+
+		mu         sync.RWMutex
+		blocks     map[cid.Cid]block
+		head       cid.Cid
+		signedHead []byte
+		// publications are the publications not yet removed, oldest first.
+		publications []*Publication
+	}
+	// Publication is a set of multihashes published under a context ID.
+	Publication struct {
+		ContextID     []byte
+		Multihashes   []multihash.Multihash
+		Advertisement cid.Cid
+		PublishedAt   time.Time
+		// released signals whether the publication may be taken for removal.
+		released bool
+	}
+	block struct {
+		data   []byte
+		schema string
+	}
+	// signedHead is the signed head advertisement served by IPNI HTTP publishers. Fields are
+	// declared in the order at which they appear in DAG-JSON.
+	signedHead struct {
+		Head   cid.Cid  `json:"head"`
+		Pubkey ad.Bytes `json:"pubkey"`
+		Sig    ad.Bytes `json:"sig"`
+		Topic  string   `json:"topic"`
+	}
+	// announcement is the message by which publishers announce new advertisements via HTTP.
+	announcement struct {
+		Cid      cid.Cid
+		Addrs    [][]byte
+		OrigPeer string `json:",omitempty"`
+	}
+)
+
+func NewPublisher(o ...Option) (*Publisher, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.publisherAddrs) == 0 {
+		return nil, errors.New("at least one publisher address must be specified")
+	}
+	if len(opts.providerAddrs) == 0 {
+		opts.providerAddrs = opts.publisherAddrs
+	}
+	id, err := loadOrGenerateIdentity(opts.identityPath)
+	if err != nil {
+		return nil, err
+	}
+	p := &Publisher{
+		options: opts,
+		id:      id,
+		blocks:  make(map[cid.Cid]block),
+	}
+	p.server = &http.Server{
+		Addr:    opts.listenAddr,
+		Handler: p,
+	}
+	return p, nil
+}
+
+// ID returns the peer ID of the provider on behalf of which the publisher publishes.
+func (p *Publisher) ID() string {
+	return p.id.peerID
+}
+
+func (p *Publisher) Start(_ context.Context) error {
+	ln, err := net.Listen("tcp", p.server.Addr)
+	if err != nil {
+		return err
+	}
+	go func() { _ = p.server.Serve(ln) }()
+	logger.Infow("Publisher started", "httpAddr", ln.Addr(), "peerID", p.id.peerID, "addrs", p.publisherAddrs)
+	return nil
+}
+
+func (p *Publisher) Shutdown(ctx context.Context) error {
+	return p.server.Shutdown(ctx)
+}
+
+// Publish publishes an advertisement for the given multihashes under the given context ID, and
+// announces it to the configured announce URLs. The returned error is non-nil if the
+// advertisement failed to publish or to announce; in the latter case the advertisement is
+// published regardless, and its CID is returned. The publication is tracked until it is removed,
+// but is not taken by TakeOldest until it is released via Release.
+func (p *Publisher) Publish(ctx context.Context, contextID []byte, mhs []multihash.Multihash) (cid.Cid, error) {
+	entries := make([]ad.Bytes, 0, len(mhs))
+	for _, mh := range mhs {
+		entries = append(entries, ad.Bytes(mh))
+	}
+	p.mu.Lock()
+	adCid, err := p.appendAd(&ad.Advertisement{ContextID: contextID}, &ad.EntryChunk{Entries: entries})
+	if err == nil {
+		p.publications = append(p.publications, &Publication{
+			ContextID:     contextID,
+			Multihashes:   mhs,
+			Advertisement: adCid,
+			PublishedAt:   time.Now(),
+		})
+		if len(p.publications) > maxPublications {
+			p.publications = p.publications[len(p.publications)-maxPublications:]
+		}
+	}
+	p.mu.Unlock()
+	if err != nil {
+		return cid.Undef, err
+	}
+	return adCid, p.announce(ctx, adCid)
+}
+
+// Remove publishes a removal advertisement for the given publication, and announces it to the
+// configured announce URLs. Errors are returned as they are by Publish.
+func (p *Publisher) Remove(ctx context.Context, pub *Publication) (cid.Cid, error) {
+	p.mu.Lock()
+	adCid, err := p.appendAd(&ad.Advertisement{ContextID: pub.ContextID, IsRm: true}, nil)
+	if err == nil {
+		for i, tracked := range p.publications {
+			if bytes.Equal(tracked.ContextID, pub.ContextID) {
+				p.publications = append(p.publications[:i], p.publications[i+1:]...)
+				break
+			}
+		}
+	}
+	p.mu.Unlock()
+	if err != nil {
+		return cid.Undef, err
+	}
+	return adCid, p.announce(ctx, adCid)
+}
+
+// Release makes the publication of the given advertisement available to TakeOldest, e.g. once the
+// probe that published it is no longer timing its lookups.
+func (p *Publisher) Release(adCid cid.Cid) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, tracked := range p.publications {
+		if tracked.Advertisement.Equals(adCid) {
+			tracked.released = true
+			return
+		}
+	}
+}
+
+// TakeOldest returns the oldest released publication not yet removed, and stops tracking it so
+// that it is not returned again. Returns nil if there is no such publication.
+func (p *Publisher) TakeOldest() *Publication {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, tracked := range p.publications {
+		if tracked.released {
+			p.publications = append(p.publications[:i], p.publications[i+1:]...)
+			return tracked
+		}
+	}
+	return nil
+}
+
+// appendAd signs and stores the given advertisement, along with its entry chunk if any, as the
+// new head. The caller must hold the write lock.
+func (p *Publisher) appendAd(a *ad.Advertisement, ec *ad.EntryChunk) (cid.Cid, error) {
+	a.Entries = ad.NoEntries
+	if ec != nil {
+		ecCid, ecData, err := ad.Encode(ec)
+		if err != nil {
+			return cid.Undef, err
+		}
+		p.blocks[ecCid] = block{data: ecData, schema: "EntryChunk"}
+		a.Entries = ecCid
+	}
+	if p.head.Defined() {
+		previous := p.head
+		a.PreviousID = &previous
+	}
+	a.Provider = p.id.peerID
+	a.Addresses = p.providerAddrs
+	a.Metadata = binary.AppendUvarint(nil, transportIpfsGatewayHttpCode)
+	a.Signature = p.id.sealEnvelope(adSignatureDomain, adSignatureCodec, a.SignaturePayload())
+	adCid, adData, err := ad.Encode(a)
+	if err != nil {
+		return cid.Undef, err
+	}
+	head, err := json.Marshal(&signedHead{
+		Head:   adCid,
+		Pubkey: p.id.marshalPublicKey(),
+		Sig:    p.id.sign(append(adCid.Bytes(), p.topic...)),
+		Topic:  p.topic,
+	})
+	if err != nil {
+		return cid.Undef, err
+	}
+	p.blocks[adCid] = block{data: adData, schema: "Advertisement"}
+	p.head = adCid
+	p.signedHead = head
+	return adCid, nil
+}
+
+// announce announces the given advertisement CID to every configured announce URL.
+func (p *Publisher) announce(ctx context.Context, adCid cid.Cid) error {
+	if len(p.announceUrls) == 0 {
+		return nil
+	}
+	msg := announcement{Cid: adCid, OrigPeer: p.id.peerID}
+	for _, addr := range p.publisherAddrs {
+		// Indexers identify the publisher by the p2p component of announced addresses.
+		if !strings.Contains(addr, "/p2p/") {
+			addr = strings.TrimSuffix(addr, "/") + "/p2p/" + p.id.peerID
+		}
+		encoded, err := maddr.Encode(addr)
+		if err != nil {
+			return err
+		}
+		msg.Addrs = append(msg.Addrs, encoded)
+	}
+	body, err := json.Marshal(&msg)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, u := range p.announceUrls {
+		if err := p.put(ctx, u, body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Publisher) put(ctx context.Context, u string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unsuccessful announce to %s: %d", u, resp.StatusCode)
+	}
+	return nil
+}
+
+// ServeHTTP serves the signed head advertisement and blocks at the IPNI HTTP publisher paths.
+func (p *Publisher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusMethodNotAllowed)
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, ipniSyncPath) {
+		http.NotFound(w, r)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, ipniSyncPath)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if name == "head" {
+		if !p.head.Defined() {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(p.signedHead)
+		return
+	}
+	c, err := cid.Decode(name)
+	if err != nil {
+		http.Error(w, "invalid CID", http.StatusBadRequest)
+		return
+	}
+	b, ok := p.blocks[c]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(cidSchemaHeader, b.schema)
+	_, _ = w.Write(b.data)
+}
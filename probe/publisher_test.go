@@ -0,0 +1,111 @@
+package probe
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/multiformats/go-multihash"
+)
+
+// The vectors below are the blocks published by the test identity for multihash SHA2_256("lookout")
+// under context ID "lookout", computed independently of this package. The advertisement signature
+// envelope seals the SHA2-256 multihash of the advertisement signature payload.
+const (
+	testEntriesCid   = "baguqeeraxwcivsdsb7lv7w6tpusrwnr6v5oeaws5mhsjfbi4i2bwrup4coca"
+	testAdCid        = "baguqeerakfenb37yvfusmmemxwnii2j5jxvgafu6ojymcsgx7b55tjhmdrxq"
+	testAd           = `{"Addresses":["/dns4/example.com/tcp/443/https"],"ContextID":{"/":{"bytes":"bG9va291dA"}},"Entries":{"/":"baguqeeraxwcivsdsb7lv7w6tpusrwnr6v5oeaws5mhsjfbi4i2bwrup4coca"},"IsRm":false,"Metadata":{"/":{"bytes":"oBI"}},"Provider":"12D3KooWBtg3aaRMjxwedh83aGiUkwSxDwUZkzuJcfaqUmo7R3pq","Signature":{"/":{"bytes":"CiQIARIgHtHo+uLEoUS4vo/UtHvz07NLhxw8rPYBDw5C1HT84n4SGy9pbmRleGVyL2luZ2VzdC9hZFNpZ25hdHVyZRoiEiDkdsdYBrkuK+gDF8oKclLwgwb2vzqQJUPikbiO9xgb8ipAzSpLPY0S+WjC9ssvjw6GciXDhzU6/6E2HHMSgQZxRcxOi1hawpt79oIXGJYR9h54+Nxr1fpAZn3hE8+WkkclDQ"}}}`
+	testSignedHead   = `{"head":{"/":"baguqeerakfenb37yvfusmmemxwnii2j5jxvgafu6ojymcsgx7b55tjhmdrxq"},"pubkey":{"/":{"bytes":"CAESIB7R6PrixKFEuL6P1LR789OzS4ccPKz2AQ8OQtR0/OJ+"}},"sig":{"/":{"bytes":"2YThE3JGI7hN27WhMK+htFuuT+/KhurcXWHZ55yUdx+/PF++6BQNgtBC0vZxqju4bsVn0rpToBO/RPy/rk7NCw"}},"topic":"/indexer/ingest/mainnet"}`
+	testAnnouncement = `{"Cid":{"/":"baguqeerakfenb37yvfusmmemxwnii2j5jxvgafu6ojymcsgx7b55tjhmdrxq"},"Addrs":["NgtleGFtcGxlLmNvbQYBu7sDpQMmACQIARIgHtHo+uLEoUS4vo/UtHvz07NLhxw8rPYBDw5C1HT84n4="],"OrigPeer":"12D3KooWBtg3aaRMjxwedh83aGiUkwSxDwUZkzuJcfaqUmo7R3pq"}`
+)
+
+func TestPublisher_Publish(t *testing.T) {
+	var announced []byte
+	indexer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		announced, _ = io.ReadAll(r.Body)
+	}))
+	defer indexer.Close()
+
+	key, err := hex.DecodeString(testPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	identityPath := filepath.Join(t.TempDir(), "identity.key")
+	if err := os.WriteFile(identityPath, key, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewPublisher(
+		WithIdentityPath(identityPath),
+		WithPublisherAddrs("/dns4/example.com/tcp/443/https"),
+		WithAnnounceUrls(indexer.URL+"/announce"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mh, err := multihash.Sum([]byte("lookout"), multihash.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	adCid, err := p.Publish(context.Background(), []byte("lookout"), []multihash.Multihash{mh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adCid.String() != testAdCid {
+		t.Fatalf("expected advertisement CID %s; got %s", testAdCid, adCid)
+	}
+	if got := string(announced); got != testAnnouncement {
+		t.Fatalf("expected announcement %s; got %s", testAnnouncement, got)
+	}
+
+	for path, want := range map[string]struct {
+		body   string
+		schema string
+	}{
+		"head":         {body: testSignedHead},
+		testAdCid:      {body: testAd, schema: "Advertisement"},
+		testEntriesCid: {schema: "EntryChunk"},
+	} {
+		t.Run(path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ipniSyncPath+path, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status %d; got %d", http.StatusOK, rec.Code)
+			}
+			if want.body != "" && rec.Body.String() != want.body {
+				t.Fatalf("expected body %s; got %s", want.body, rec.Body.String())
+			}
+			if got := rec.Header().Get(cidSchemaHeader); got != want.schema {
+				t.Fatalf("expected schema %q; got %q", want.schema, got)
+			}
+		})
+	}
+}
+
+func TestPublisher_TakeOldest(t *testing.T) {
+	p, err := NewPublisher(WithPublisherAddrs("/dns4/example.com/tcp/443/https"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := p.Publish(context.Background(), []byte("first"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := p.Publish(context.Background(), []byte("second"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub := p.TakeOldest(); pub != nil {
+		t.Fatalf("expected no publication to be taken before release; got %s", pub.Advertisement)
+	}
+	p.Release(second)
+	if pub := p.TakeOldest(); pub == nil || !pub.Advertisement.Equals(second) {
+		t.Fatalf("expected released publication %s to be taken; got %v", second, pub)
+	}
+	if pub := p.TakeOldest(); pub != nil {
+		t.Fatalf("expected unreleased publication %s not to be taken; got %s", first, pub.Advertisement)
+	}
+}
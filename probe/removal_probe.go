@@ -0,0 +1,111 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ipni/lookout/check"
+)
+
+var _ Probe = (*RemovalProbe)(nil)
+
+// RemovalProbe publishes a removal advertisement for multihashes previously published via the
+// embedded publisher, then looks them up via the configured checker until none of them are found
+// with the publisher as provider, measuring the time it takes for removed content to become
+// unfindable.
+//
+// The oldest released publication not yet removed is picked, e.g. one published by a completed
+// PublishProbe run. If there is none, random multihashes are published first and awaited until
+// findable.
+type RemovalProbe struct {
+	*options
+}
+
+func NewRemovalProbe(o ...Option) (*RemovalProbe, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	if opts.name == "" {
+		return nil, errors.New("probe name must be specified")
+	}
+	if opts.publisher == nil {
+		return nil, errors.New("publisher must be specified")
+	}
+	if opts.checker == nil {
+		return nil, errors.New("checker must be specified")
+	}
+	return &RemovalProbe{options: opts}, nil
+}
+
+func (p *RemovalProbe) Probe(ctx context.Context) *Result {
+	result := &Result{
+		ProbeName: p.name,
+		Kind:      KindUnfindable,
+		Provider:  p.publisher.ID(),
+	}
+	pub := p.publisher.TakeOldest()
+	if pub == nil {
+		contextID, mhs, err := randomContent(p.entries)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		adCid, err := p.publisher.Publish(ctx, contextID, mhs)
+		if !adCid.Defined() {
+			result.Err = err
+			return result
+		}
+		pub = &Publication{ContextID: contextID, Multihashes: mhs, Advertisement: adCid, PublishedAt: time.Now()}
+	}
+	result.Multihashes = pub.Multihashes
+
+	// Wait for the multihashes to be findable before removing them, so that them becoming
+	// unfindable is attributable to the removal.
+	if err := p.pollUntilFindable(ctx, pub.Multihashes); err != nil {
+		result.Err = fmt.Errorf("multihashes to remove are not findable: %w", err)
+		// Remove regardless so that the probe does not leave records behind.
+		if _, err := p.publisher.Remove(ctx, pub); err != nil {
+			logger.Warnw("Failed to remove unfindable publication", "probe", p.name, "ad", pub.Advertisement, "err", err)
+		}
+		return result
+	}
+
+	start := time.Now()
+	var err error
+	result.Advertisement, err = p.publisher.Remove(ctx, pub)
+	if !result.Advertisement.Defined() {
+		result.Err = err
+		return result
+	}
+	if err != nil {
+		// The advertisement is published regardless; the indexer may still discover it.
+		logger.Warnw("Failed to announce removal advertisement", "probe", p.name, "ad", result.Advertisement, "err", err)
+	}
+	result.Err = p.pollUntil(ctx, pub.Multihashes, "unfindable", p.lostPublisher)
+	result.Elapsed = time.Since(start)
+	result.Succeeded = result.Err == nil
+	return result
+}
+
+// lostPublisher checks whether the given lookup completed without finding the publisher as a
+// provider. Failed lookups are not considered evidence of removal.
+func (o *options) lostPublisher(r *check.Result) bool {
+	switch {
+	case r.Err != nil:
+		return false
+	case r.StatusCode == http.StatusNotFound:
+		return true
+	case r.StatusCode != http.StatusOK || r.DecodeErr != nil:
+		return false
+	}
+	for _, id := range r.PeerIDs {
+		if id == o.publisher.ID() {
+			return false
+		}
+	}
+	return true
+}
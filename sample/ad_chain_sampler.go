@@ -0,0 +1,57 @@
+package sample
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ipfs/go-cid"
+)
+
+var _ Sampler = (*AdChainSampler)(nil)
+
+// AdChainSampler samples multihashes advertised by a provider, by walking back the advertisement
+// chain published by its IPNI HTTP publisher. The resulting set is tagged with the provider ID,
+// which allows checking whether what a provider advertised actually became findable.
+//
+// Only DAG-JSON encoded advertisements and entry chunks are supported.
+type AdChainSampler struct {
+	*options
+}
+
+func NewAdChainSampler(o ...Option) (*AdChainSampler, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	if opts.publisherUrl == "" {
+		return nil, errors.New("publisher URL must be specified")
+	}
+	return &AdChainSampler{options: opts}, nil
+}
+
+func (s *AdChainSampler) Sample(ctx context.Context) (*Set, error) {
+	p, err := newPublisherClient(s.publisherUrl)
+	if err != nil {
+		return nil, err
+	}
+	head, err := p.head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	provider, mhs, err := s.sampleAdChain(ctx, p, head)
+	if err != nil {
+		return nil, err
+	}
+	cids := s.newCidSet()
+	for _, mh := range mhs {
+		cids.Add(cid.NewCidV1(cid.Raw, mh))
+	}
+	if cids.Len() == 0 {
+		logger.Warnw("No multihashes were found in advertisement chain", "publisher", s.publisherUrl, "head", head)
+	}
+	return &Set{
+		Cids:     cids.Cids(),
+		Name:     s.name,
+		Provider: provider,
+	}, nil
+}
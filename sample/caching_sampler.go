@@ -0,0 +1,64 @@
+package sample
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var _ Sampler = (*CachingSampler)(nil)
+
+// CachingSampler wraps a Sampler and caches the last sample set it successfully produced. The
+// cached set is served until it is older than the configured cache TTL, after which the wrapped
+// sampler is sampled again. When the wrapped sampler fails, the cached set is served regardless of
+// its age so that outages of the sampled source do not interrupt checks.
+type CachingSampler struct {
+	*options
+	sampler Sampler
+
+	mu     sync.Mutex
+	cached *Set
+}
+
+func NewCachingSampler(sampler Sampler, o ...Option) (*CachingSampler, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	if opts.cacheTTL <= 0 {
+		return nil, errors.New("cache TTL must be specified")
+	}
+	return &CachingSampler{
+		options: opts,
+		sampler: sampler,
+	}, nil
+}
+
+func (s *CachingSampler) Sample(ctx context.Context) (*Set, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cached != nil && time.Since(s.cached.SampledAt) < s.cacheTTL {
+		return s.cachedCopy(), nil
+	}
+	set, err := s.sampler.Sample(ctx)
+	if err != nil {
+		if s.cached == nil {
+			return nil, err
+		}
+		logger.Warnw("Failed to sample; serving cached sample set", "name", s.name, "age", time.Since(s.cached.SampledAt), "err", err)
+		return s.cachedCopy(), nil
+	}
+	if set.SampledAt.IsZero() {
+		set.SampledAt = time.Now()
+	}
+	s.cached = set
+	return s.cachedCopy(), nil
+}
+
+// cachedCopy returns a shallow copy of the cached set so that callers cannot alter it. The CIDs
+// are shared and must not be modified.
+func (s *CachingSampler) cachedCopy() *Set {
+	set := *s.cached
+	return &set
+}
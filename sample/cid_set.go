@@ -1,36 +1,83 @@
 package sample
 
 import (
-	"hash/crc32"
-
 	"github.com/ipfs/go-cid"
 )
 
-type cidSet struct {
-	hashset map[uint32]struct{}
-	cids    []cid.Cid
+// CidSet is an insertion-ordered set of CIDs. By default, CIDs are deduplicated by multihash,
+// matching how IPNI keys its records: CIDs that differ only in version or codec are considered
+// the same entry, and the first one added is kept.
+type CidSet struct {
+	byCid bool
+	// keys maps the deduplication key of each CID to its index in cids.
+	keys map[string]int
+	cids []cid.Cid
+}
+
+// NewCidSet instantiates a new CidSet that deduplicates CIDs by multihash.
+func NewCidSet() *CidSet {
+	return &CidSet{
+		keys: make(map[string]int),
+	}
+}
+
+// NewCidSetByCid instantiates a new CidSet that deduplicates CIDs by their full binary form, i.e.
+// version, codec and multihash.
+func NewCidSetByCid() *CidSet {
+	return &CidSet{
+		byCid: true,
+		keys:  make(map[string]int),
+	}
 }
 
-func newCidSet() *cidSet {
-	return &cidSet{
-		hashset: make(map[uint32]struct{}),
+// newCidSet instantiates a new CidSet that deduplicates according to the configured options.
+func (o *options) newCidSet() *CidSet {
+	if o.dedupeByCid {
+		return NewCidSetByCid()
 	}
+	return NewCidSet()
 }
 
-func (cs *cidSet) putIfAbsent(c cid.Cid) bool {
-	key := crc32.ChecksumIEEE(c.Bytes())
-	_, seen := cs.hashset[key]
+// Add adds the given CID to the set if absent, and returns true if it was added.
+func (cs *CidSet) Add(c cid.Cid) bool {
+	key := cs.key(c)
+	if _, seen := cs.keys[key]; seen {
+		return false
+	}
+	cs.keys[key] = len(cs.cids)
+	cs.cids = append(cs.cids, c)
+	return true
+}
+
+// Get returns the CID kept in the set in place of the given CID, i.e. the first one added that
+// is considered the same entry, along with whether there is such a CID.
+func (cs *CidSet) Get(c cid.Cid) (cid.Cid, bool) {
+	i, seen := cs.keys[cs.key(c)]
 	if !seen {
-		cs.hashset[key] = struct{}{}
-		cs.cids = append(cs.cids, c)
+		return cid.Undef, false
 	}
-	return !seen
+	return cs.cids[i], true
+}
+
+// Has checks whether the given CID is present in the set.
+func (cs *CidSet) Has(c cid.Cid) bool {
+	_, seen := cs.keys[cs.key(c)]
+	return seen
 }
 
-func (cs *cidSet) len() int {
+// Len returns the number of CIDs in the set.
+func (cs *CidSet) Len() int {
 	return len(cs.cids)
 }
 
-func (cs *cidSet) slice() []cid.Cid {
+// Cids returns the CIDs in the set in the order in which they were added.
+func (cs *CidSet) Cids() []cid.Cid {
 	return cs.cids
 }
+
+func (cs *CidSet) key(c cid.Cid) string {
+	if cs.byCid {
+		return c.KeyString()
+	}
+	return string(c.Hash())
+}
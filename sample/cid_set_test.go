@@ -0,0 +1,111 @@
+package sample
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+func TestCidSet(t *testing.T) {
+	mh := testMultihash(t, "fish")
+	v0 := cid.NewCidV0(mh)
+	v1 := cid.NewCidV1(cid.Raw, mh)
+	other := cid.NewCidV1(cid.Raw, testMultihash(t, "lobster"))
+
+	tests := []struct {
+		name     string
+		set      *CidSet
+		add      []cid.Cid
+		wantCids []cid.Cid
+	}{
+		{
+			name:     "by multihash",
+			set:      NewCidSet(),
+			add:      []cid.Cid{v0, other, v1, v0},
+			wantCids: []cid.Cid{v0, other},
+		},
+		{
+			name:     "by cid",
+			set:      NewCidSetByCid(),
+			add:      []cid.Cid{v0, other, v1, v0},
+			wantCids: []cid.Cid{v0, other, v1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, c := range test.add {
+				test.set.Add(c)
+			}
+			assertCids(t, test.wantCids, test.set.Cids())
+			for _, c := range test.add {
+				if !test.set.Has(c) {
+					t.Fatalf("expected set to have %s", c)
+				}
+			}
+		})
+	}
+}
+
+func TestCidSet_Get(t *testing.T) {
+	mh := testMultihash(t, "fish")
+	v0 := cid.NewCidV0(mh)
+	v1 := cid.NewCidV1(cid.Raw, mh)
+
+	set := NewCidSet()
+	if _, ok := set.Get(v0); ok {
+		t.Fatal("expected empty set to have no CID")
+	}
+	set.Add(v0)
+	if added := set.Add(v1); added {
+		t.Fatalf("expected %s not to be added alongside %s", v1, v0)
+	}
+	if kept, ok := set.Get(v1); !ok || !kept.Equals(v0) {
+		t.Fatalf("expected %s to be kept in place of %s; got %s", v0, v1, kept)
+	}
+
+	byCid := NewCidSetByCid()
+	byCid.Add(v0)
+	if _, ok := byCid.Get(v1); ok {
+		t.Fatalf("expected %s to be absent from set deduplicated by CID", v1)
+	}
+}
+
+func testMultihash(t *testing.T, data string) multihash.Multihash {
+	t.Helper()
+	mh, err := multihash.Sum([]byte(data), multihash.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mh
+}
+
+func testCid(t *testing.T, data string) cid.Cid {
+	t.Helper()
+	return cid.NewCidV1(cid.Raw, testMultihash(t, data))
+}
+
+func assertCids(t *testing.T, want, got []cid.Cid) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected CIDs %v; got %v", want, got)
+	}
+	for i := range want {
+		if !got[i].Equals(want[i]) {
+			t.Fatalf("expected CIDs %v; got %v", want, got)
+		}
+	}
+}
+
+func assertPaths(t *testing.T, want, got map[cid.Cid][]string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected paths %v; got %v", want, got)
+	}
+	for c, ps := range want {
+		if fmt.Sprint(got[c]) != fmt.Sprint(ps) {
+			t.Fatalf("expected paths %v; got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package sample
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/ipfs/go-cid"
+)
+
+var _ Sampler = (*CompositeSampler)(nil)
+
+const (
+	// CompositionUnion samples the CIDs present in any of the sources.
+	CompositionUnion Composition = "union"
+	// CompositionIntersection samples the CIDs present in all of the sources.
+	CompositionIntersection Composition = "intersection"
+	// CompositionDifference samples the CIDs present in the first source but in none of the others.
+	CompositionDifference Composition = "difference"
+	// CompositionMix samples CIDs at random from every source in proportion to its weight.
+	CompositionMix Composition = "mix"
+)
+
+type (
+	// Composition represents the operation by which CompositeSampler combines its sources.
+	Composition string
+
+	// CompositeSampler combines the sample sets of other samplers into a single sample set.
+	CompositeSampler struct {
+		*options
+	}
+	compositeSource struct {
+		sampler Sampler
+		weight  float64
+	}
+)
+
+func NewCompositeSampler(o ...Option) (*CompositeSampler, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	switch opts.composition {
+	case CompositionUnion, CompositionIntersection, CompositionDifference, CompositionMix:
+	case "":
+		return nil, errors.New("composition must be specified")
+	default:
+		return nil, fmt.Errorf("unknown composition: %s", opts.composition)
+	}
+	if len(opts.sources) == 0 {
+		return nil, errors.New("at least one source must be specified")
+	}
+	if opts.composition == CompositionMix {
+		var total float64
+		for _, source := range opts.sources {
+			total += source.weight
+		}
+		if total <= 0 {
+			return nil, errors.New("at least one source must have a positive weight")
+		}
+	}
+	return &CompositeSampler{options: opts}, nil
+}
+
+func (s *CompositeSampler) Sample(ctx context.Context) (*Set, error) {
+	sets, paths, err := s.sampleSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var cids *CidSet
+	switch s.composition {
+	case CompositionUnion:
+		cids = s.union(sets)
+	case CompositionIntersection:
+		cids = s.intersection(sets)
+	case CompositionDifference:
+		cids = s.difference(sets)
+	case CompositionMix:
+		cids = s.mix(sets)
+	}
+	if cids.Len() == 0 {
+		logger.Warnw("No CIDs were found in composition of sources", "name", s.name, "composition", s.composition)
+	}
+	return &Set{
+		Cids:  cids.Cids(),
+		Name:  s.name,
+		Paths: composePaths(cids, paths),
+	}, nil
+}
+
+// sampleSources samples all sources concurrently, and returns their sets and paths in the order in
+// which the sources were specified. Fails if any of the sources fails, since the composition of a
+// partial set of sources would be misleading.
+func (s *CompositeSampler) sampleSources(ctx context.Context) ([]*CidSet, []map[cid.Cid][]string, error) {
+	sets := make([]*CidSet, len(s.sources))
+	sourcePaths := make([]map[cid.Cid][]string, len(s.sources))
+	errs := make([]error, len(s.sources))
+	var wg sync.WaitGroup
+	for i, source := range s.sources {
+		wg.Add(1)
+		go func(i int, source compositeSource) {
+			defer wg.Done()
+			set, err := source.sampler.Sample(ctx)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			sets[i] = s.newCidSet()
+			for _, c := range set.Cids {
+				sets[i].Add(c)
+			}
+			sourcePaths[i] = set.Paths
+		}(i, source)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to sample source %d of %s: %w", i, s.name, err)
+		}
+	}
+	return sets, sourcePaths, nil
+}
+
+// composePaths merges the given source paths of the CIDs in the composed set, keyed by the CID
+// kept in the set, since sources may sample the same multihash under different CIDs. Returns nil
+// if none of the composed CIDs have paths.
+func composePaths(cids *CidSet, sourcePaths []map[cid.Cid][]string) map[cid.Cid][]string {
+	composed := make(map[cid.Cid][]string)
+	for _, sp := range sourcePaths {
+		for c, ps := range sp {
+			kept, ok := cids.Get(c)
+			if !ok {
+				continue
+			}
+		Paths:
+			for _, p := range ps {
+				for _, existing := range composed[kept] {
+					if existing == p {
+						continue Paths
+					}
+				}
+				composed[kept] = append(composed[kept], p)
+			}
+		}
+	}
+	if len(composed) == 0 {
+		return nil
+	}
+	return composed
+}
+
+func (s *CompositeSampler) union(sets []*CidSet) *CidSet {
+	cids := s.newCidSet()
+	for _, set := range sets {
+		for _, c := range set.Cids() {
+			cids.Add(c)
+		}
+	}
+	return cids
+}
+
+func (s *CompositeSampler) intersection(sets []*CidSet) *CidSet {
+	cids := s.newCidSet()
+Candidates:
+	for _, c := range sets[0].Cids() {
+		for _, other := range sets[1:] {
+			if !other.Has(c) {
+				continue Candidates
+			}
+		}
+		cids.Add(c)
+	}
+	return cids
+}
+
+func (s *CompositeSampler) difference(sets []*CidSet) *CidSet {
+	cids := s.newCidSet()
+Candidates:
+	for _, c := range sets[0].Cids() {
+		for _, other := range sets[1:] {
+			if other.Has(c) {
+				continue Candidates
+			}
+		}
+		cids.Add(c)
+	}
+	return cids
+}
+
+// mix draws CIDs at random from every source in proportion to its weight. The total number of
+// CIDs drawn is the configured maximum set size if any. Otherwise, it is the largest total that
+// every source can contribute its share of. Sources that sampled no CIDs are skipped, and their
+// weight is spread across the others in proportion to their weights.
+func (s *CompositeSampler) mix(sets []*CidSet) *CidSet {
+	var totalWeight float64
+	for i, source := range s.sources {
+		if sets[i].Len() != 0 {
+			totalWeight += source.weight
+		}
+	}
+	if totalWeight <= 0 {
+		return s.newCidSet()
+	}
+	total := s.maxSetSize
+	if total <= 0 {
+		total = math.MaxInt
+		for i, source := range s.sources {
+			if source.weight <= 0 || sets[i].Len() == 0 {
+				continue
+			}
+			if achievable := int(float64(sets[i].Len()) * totalWeight / source.weight); achievable < total {
+				total = achievable
+			}
+		}
+	}
+	rng := s.newRand()
+	cids := s.newCidSet()
+	for i, source := range s.sources {
+		share := int(math.Round(float64(total) * source.weight / totalWeight))
+		candidates := sets[i].Cids()
+		// Draw without replacement via partial Fisher-Yates shuffle over a copy, skipping CIDs
+		// already drawn from other sources.
+		shuffled := make([]cid.Cid, len(candidates))
+		copy(shuffled, candidates)
+		for j, drawn := 0, 0; j < len(shuffled) && drawn < share; j++ {
+			k := j + rng.Intn(len(shuffled)-j)
+			shuffled[j], shuffled[k] = shuffled[k], shuffled[j]
+			if cids.Add(shuffled[j]) {
+				drawn++
+			}
+		}
+	}
+	return cids
+}
@@ -0,0 +1,212 @@
+package sample
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+)
+
+var errTestSampler = errors.New("test sampler failure")
+
+type (
+	// staticSet is a Sampler that always returns the same set.
+	staticSet Set
+	// failingSampler is a Sampler that always fails.
+	failingSampler struct{}
+)
+
+func (s *staticSet) Sample(context.Context) (*Set, error) {
+	set := Set(*s)
+	return &set, nil
+}
+
+func (failingSampler) Sample(context.Context) (*Set, error) {
+	return nil, errTestSampler
+}
+
+func TestCompositeSampler(t *testing.T) {
+	a, b, c, d := testCid(t, "a"), testCid(t, "b"), testCid(t, "c"), testCid(t, "d")
+	first := &staticSet{Cids: []cid.Cid{a, b, c}, Paths: map[cid.Cid][]string{a: {"x"}, b: {"y"}}}
+	second := &staticSet{Cids: []cid.Cid{b, d}, Paths: map[cid.Cid][]string{b: {"y", "z"}, d: {"w"}}}
+	third := &staticSet{Cids: []cid.Cid{c, b}}
+
+	tests := []struct {
+		composition Composition
+		wantCids    []cid.Cid
+		wantPaths   map[cid.Cid][]string
+	}{
+		{
+			composition: CompositionUnion,
+			wantCids:    []cid.Cid{a, b, c, d},
+			wantPaths:   map[cid.Cid][]string{a: {"x"}, b: {"y", "z"}, d: {"w"}},
+		},
+		{
+			composition: CompositionIntersection,
+			wantCids:    []cid.Cid{b},
+			wantPaths:   map[cid.Cid][]string{b: {"y", "z"}},
+		},
+		{
+			composition: CompositionDifference,
+			wantCids:    []cid.Cid{a},
+			wantPaths:   map[cid.Cid][]string{a: {"x"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(string(test.composition), func(t *testing.T) {
+			s, err := NewCompositeSampler(
+				WithName("composite"),
+				WithComposition(test.composition),
+				WithSource(first, 1),
+				WithSource(second, 1),
+				WithSource(third, 1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			set, err := s.Sample(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if set.Name != "composite" {
+				t.Fatalf("expected name composite; got %s", set.Name)
+			}
+			assertCids(t, test.wantCids, set.Cids)
+			assertPaths(t, test.wantPaths, set.Paths)
+		})
+	}
+}
+
+func TestCompositeSampler_PathsOfEquivalentCids(t *testing.T) {
+	mh := testMultihash(t, "fish")
+	v0, v1 := cid.NewCidV0(mh), cid.NewCidV1(cid.Raw, mh)
+	s, err := NewCompositeSampler(
+		WithName("composite"),
+		WithComposition(CompositionUnion),
+		WithSource(&staticSet{Cids: []cid.Cid{v0}, Paths: map[cid.Cid][]string{v0: {"x"}}}, 1),
+		WithSource(&staticSet{Cids: []cid.Cid{v1}, Paths: map[cid.Cid][]string{v1: {"y"}}}, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := s.Sample(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertCids(t, []cid.Cid{v0}, set.Cids)
+	assertPaths(t, map[cid.Cid][]string{v0: {"x", "y"}}, set.Paths)
+}
+
+func TestCompositeSampler_SourceFailure(t *testing.T) {
+	s, err := NewCompositeSampler(
+		WithName("composite"),
+		WithComposition(CompositionUnion),
+		WithSource(&staticSet{Cids: []cid.Cid{testCid(t, "a")}}, 1),
+		WithSource(failingSampler{}, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Sample(context.Background()); !errors.Is(err, errTestSampler) {
+		t.Fatalf("expected source error; got %v", err)
+	}
+}
+
+func TestNewCompositeSampler_Invalid(t *testing.T) {
+	source := &staticSet{}
+	tests := map[string][]Option{
+		"no composition":      {WithName("composite"), WithSource(source, 1)},
+		"unknown composition": {WithName("composite"), WithComposition("xor"), WithSource(source, 1)},
+		"no sources":          {WithName("composite"), WithComposition(CompositionUnion)},
+		"zero total weight":   {WithName("composite"), WithComposition(CompositionMix), WithSource(source, 0)},
+		"negative weight":     {WithName("composite"), WithComposition(CompositionMix), WithSource(source, -1)},
+	}
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewCompositeSampler(opts...); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestCompositeSampler_MixSkipsEmptySources(t *testing.T) {
+	full := &staticSet{Cids: []cid.Cid{testCid(t, "a"), testCid(t, "b"), testCid(t, "c"), testCid(t, "d")}}
+	empty := &staticSet{}
+	half := &staticSet{Cids: []cid.Cid{testCid(t, "e"), testCid(t, "f")}}
+
+	tests := []struct {
+		name    string
+		maxSize int
+		want    int
+	}{
+		{
+			// Without the empty source, at most 4 CIDs can be drawn in equal shares.
+			name: "unbounded",
+			want: 4,
+		},
+		{
+			name:    "bounded",
+			maxSize: 2,
+			want:    2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := []Option{
+				WithName("mix"),
+				WithComposition(CompositionMix),
+				WithSeed(1413),
+				WithSource(full, 1),
+				WithSource(empty, 1),
+				WithSource(half, 1),
+			}
+			if test.maxSize != 0 {
+				opts = append(opts, WithMaxSetSize(test.maxSize))
+			}
+			s, err := NewCompositeSampler(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			set, err := s.Sample(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(set.Cids) != test.want {
+				t.Fatalf("expected %d CIDs; got %d: %v", test.want, len(set.Cids), set.Cids)
+			}
+			var fromFull, fromHalf int
+			for _, c := range set.Cids {
+				for _, fc := range full.Cids {
+					if c.Equals(fc) {
+						fromFull++
+					}
+				}
+				for _, hc := range half.Cids {
+					if c.Equals(hc) {
+						fromHalf++
+					}
+				}
+			}
+			if fromFull != fromHalf {
+				t.Fatalf("expected equal shares from non-empty sources; got %d and %d", fromFull, fromHalf)
+			}
+		})
+	}
+}
+
+func TestCompositeSampler_MixAllEmpty(t *testing.T) {
+	s, err := NewCompositeSampler(
+		WithName("mix"),
+		WithComposition(CompositionMix),
+		WithSource(&staticSet{}, 1),
+		WithSource(&staticSet{}, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := s.Sample(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Cids) != 0 {
+		t.Fatalf("expected no CIDs; got %v", set.Cids)
+	}
+}
@@ -0,0 +1,229 @@
+package sample
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	_ Extractor = (*jsonPathExtractor)(nil)
+	_ Extractor = (*csvColumnExtractor)(nil)
+	_ Extractor = (*regexExtractor)(nil)
+	_ Extractor = (*linesExtractor)(nil)
+)
+
+type (
+	// Extractor extracts string values, such as CIDs, from a response body.
+	Extractor interface {
+		Extract(io.Reader) ([]string, error)
+	}
+	jsonPathExtractor struct {
+		steps []jsonPathStep
+	}
+	// jsonPathStep is either a field selector, an index selector or a wildcard.
+	jsonPathStep struct {
+		field    string
+		index    int
+		wildcard bool
+	}
+	csvColumnExtractor struct {
+		name  string
+		index int
+	}
+	regexExtractor struct {
+		pattern *regexp.Regexp
+	}
+	linesExtractor struct{}
+)
+
+// NewJsonPathExtractor instantiates an extractor that selects string values from a JSON document
+// using a subset of JSONPath syntax: dot-notation field selectors, bracket-notation index
+// selectors and wildcards, e.g. "$.items[*].cid" or "$[*]". Selected arrays of strings are
+// flattened.
+func NewJsonPathExtractor(path string) (Extractor, error) {
+	steps, err := parseJsonPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonPathExtractor{steps: steps}, nil
+}
+
+// NewCsvColumnExtractor instantiates an extractor that selects a column from CSV records. The
+// column is either a zero-based index, or the name of a column in the header record.
+func NewCsvColumnExtractor(column string) (Extractor, error) {
+	if column == "" {
+		return nil, errors.New("csv column must be specified")
+	}
+	if index, err := strconv.Atoi(column); err == nil {
+		if index < 0 {
+			return nil, fmt.Errorf("csv column index cannot be negative; got %d", index)
+		}
+		return &csvColumnExtractor{index: index}, nil
+	}
+	return &csvColumnExtractor{name: column, index: -1}, nil
+}
+
+// NewRegexExtractor instantiates an extractor that selects all matches of the given regular
+// expression. If the expression has a capturing group, the first group is selected instead of the
+// entire match.
+func NewRegexExtractor(pattern string) (Extractor, error) {
+	p, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &regexExtractor{pattern: p}, nil
+}
+
+// NewLinesExtractor instantiates an extractor that selects newline separated values. Empty lines
+// and lines starting with # are ignored.
+func NewLinesExtractor() Extractor {
+	return &linesExtractor{}
+}
+
+func (e *jsonPathExtractor) Extract(r io.Reader) ([]string, error) {
+	var doc any
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	nodes := []any{doc}
+	for _, step := range e.steps {
+		var next []any
+		for _, node := range nodes {
+			switch n := node.(type) {
+			case map[string]any:
+				if step.wildcard {
+					for _, v := range n {
+						next = append(next, v)
+					}
+				} else if v, ok := n[step.field]; ok && step.field != "" {
+					next = append(next, v)
+				}
+			case []any:
+				if step.wildcard {
+					next = append(next, n...)
+				} else if step.field == "" && step.index >= 0 && step.index < len(n) {
+					next = append(next, n[step.index])
+				}
+			}
+		}
+		nodes = next
+	}
+	var values []string
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case string:
+			values = append(values, n)
+		case []any:
+			for _, v := range n {
+				if s, ok := v.(string); ok {
+					values = append(values, s)
+				}
+			}
+		}
+	}
+	return values, nil
+}
+
+func parseJsonPath(path string) ([]jsonPathStep, error) {
+	p := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var steps []jsonPathStep
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			field := p[:end]
+			p = p[end:]
+			switch field {
+			case "":
+				return nil, fmt.Errorf("empty field in json path: %s", path)
+			case "*":
+				steps = append(steps, jsonPathStep{wildcard: true})
+			default:
+				steps = append(steps, jsonPathStep{field: field})
+			}
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket in json path: %s", path)
+			}
+			selector := p[1:end]
+			p = p[end+1:]
+			if selector == "*" {
+				steps = append(steps, jsonPathStep{wildcard: true})
+				continue
+			}
+			if unquoted := strings.Trim(selector, `'"`); unquoted != selector {
+				steps = append(steps, jsonPathStep{field: unquoted})
+				continue
+			}
+			index, err := strconv.Atoi(selector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %s in json path: %s", selector, path)
+			}
+			steps = append(steps, jsonPathStep{index: index})
+		default:
+			return nil, fmt.Errorf("unexpected character %q in json path: %s", p[0], path)
+		}
+	}
+	return steps, nil
+}
+
+func (e *csvColumnExtractor) Extract(r io.Reader) ([]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	index := e.index
+	var values []string
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if index < 0 {
+			for i, name := range record {
+				if strings.TrimSpace(name) == e.name {
+					index = i
+				}
+			}
+			if index < 0 {
+				return nil, fmt.Errorf("csv column not found in header: %s", e.name)
+			}
+			continue
+		}
+		if index < len(record) {
+			values = append(values, strings.TrimSpace(record[index]))
+		}
+	}
+}
+
+func (e *regexExtractor) Extract(r io.Reader) ([]string, error) {
+	all, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for _, match := range e.pattern.FindAllSubmatch(all, -1) {
+		if len(match) > 1 {
+			values = append(values, string(match[1]))
+		} else {
+			values = append(values, string(match[0]))
+		}
+	}
+	return values, nil
+}
+
+func (e *linesExtractor) Extract(r io.Reader) ([]string, error) {
+	return readValues(r, FormatText)
+}
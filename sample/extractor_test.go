@@ -0,0 +1,173 @@
+package sample
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseJsonPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []jsonPathStep
+		wantErr bool
+	}{
+		{path: "$", want: nil},
+		{path: "", want: nil},
+		{path: "$.cids", want: []jsonPathStep{{field: "cids"}}},
+		{path: " $.items[*].cid ", want: []jsonPathStep{{field: "items"}, {wildcard: true}, {field: "cid"}}},
+		{path: "$[*]", want: []jsonPathStep{{wildcard: true}}},
+		{path: "$.*", want: []jsonPathStep{{wildcard: true}}},
+		{path: "$[2]", want: []jsonPathStep{{index: 2}}},
+		{path: `$['odd.name']["other"]`, want: []jsonPathStep{{field: "odd.name"}, {field: "other"}}},
+		{path: "$.a.b[0].c", want: []jsonPathStep{{field: "a"}, {field: "b"}, {index: 0}, {field: "c"}}},
+		{path: "$..a", wantErr: true},
+		{path: "$.a.", wantErr: true},
+		{path: "$[0", wantErr: true},
+		{path: "$[x]", wantErr: true},
+		{path: "a.b", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			got, err := parseJsonPath(test.path)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error parsing %q; got steps %+v", test.path, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("expected steps %+v; got %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestExtractors(t *testing.T) {
+	tests := []struct {
+		name      string
+		extractor func() (Extractor, error)
+		body      string
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "json path root array",
+			extractor: func() (Extractor, error) { return NewJsonPathExtractor("$") },
+			body:      `["a","b",1,"c"]`,
+			want:      []string{"a", "b", "c"},
+		},
+		{
+			name:      "json path nested wildcard",
+			extractor: func() (Extractor, error) { return NewJsonPathExtractor("$.items[*].cid") },
+			body:      `{"items":[{"cid":"a"},{"other":"x"},{"cid":"b"},"c"]}`,
+			want:      []string{"a", "b"},
+		},
+		{
+			name:      "json path index",
+			extractor: func() (Extractor, error) { return NewJsonPathExtractor("$.items[1]") },
+			body:      `{"items":["a","b","c"]}`,
+			want:      []string{"b"},
+		},
+		{
+			name:      "json path index out of range",
+			extractor: func() (Extractor, error) { return NewJsonPathExtractor("$.items[3]") },
+			body:      `{"items":["a","b","c"]}`,
+		},
+		{
+			name:      "json path object wildcard",
+			extractor: func() (Extractor, error) { return NewJsonPathExtractor("$.*") },
+			body:      `{"x":"a","y":["b","c"],"z":{"w":"d"}}`,
+			want:      []string{"a", "b", "c"},
+		},
+		{
+			name:      "json path missing field",
+			extractor: func() (Extractor, error) { return NewJsonPathExtractor("$.missing") },
+			body:      `{"cids":["a"]}`,
+		},
+		{
+			name:      "json path invalid body",
+			extractor: func() (Extractor, error) { return NewJsonPathExtractor("$") },
+			body:      `{`,
+			wantErr:   true,
+		},
+		{
+			name:      "csv column index",
+			extractor: func() (Extractor, error) { return NewCsvColumnExtractor("1") },
+			body:      "x, a\ny,b \nshort\n",
+			want:      []string{"a", "b"},
+		},
+		{
+			name:      "csv column name",
+			extractor: func() (Extractor, error) { return NewCsvColumnExtractor("cid") },
+			body:      "rank, cid\n1,a\n2,b\n",
+			want:      []string{"a", "b"},
+		},
+		{
+			name:      "csv column name missing",
+			extractor: func() (Extractor, error) { return NewCsvColumnExtractor("cid") },
+			body:      "rank,value\n1,a\n",
+			wantErr:   true,
+		},
+		{
+			name:      "csv column negative index",
+			extractor: func() (Extractor, error) { return NewCsvColumnExtractor("-1") },
+			wantErr:   true,
+		},
+		{
+			name:      "csv column empty",
+			extractor: func() (Extractor, error) { return NewCsvColumnExtractor("") },
+			wantErr:   true,
+		},
+		{
+			name:      "regex whole match",
+			extractor: func() (Extractor, error) { return NewRegexExtractor(`bafy[a-z0-9]+`) },
+			body:      "see bafyabc and bafydef.",
+			want:      []string{"bafyabc", "bafydef"},
+		},
+		{
+			name:      "regex capturing group",
+			extractor: func() (Extractor, error) { return NewRegexExtractor(`/ipfs/([a-z0-9]+)`) },
+			body:      `<a href="/ipfs/abc">x</a> <a href="/ipfs/def">y</a>`,
+			want:      []string{"abc", "def"},
+		},
+		{
+			name:      "regex invalid",
+			extractor: func() (Extractor, error) { return NewRegexExtractor(`(`) },
+			wantErr:   true,
+		},
+		{
+			name:      "lines",
+			extractor: func() (Extractor, error) { return NewLinesExtractor(), nil },
+			body:      "a\n\n# comment\n  b  \n",
+			want:      []string{"a", "b"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e, err := test.extractor()
+			var got []string
+			if err == nil {
+				got, err = e.Extract(strings.NewReader(test.body))
+			}
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error; got values %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Object wildcards select values in no particular order.
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("expected values %v; got %v", test.want, got)
+			}
+		})
+	}
+}
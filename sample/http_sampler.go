@@ -0,0 +1,68 @@
+package sample
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var _ Sampler = (*HttpSampler)(nil)
+
+// HttpSampler samples CIDs or base58 encoded multihashes from the response to an HTTP GET request,
+// using a configurable Extractor.
+type HttpSampler struct {
+	*options
+}
+
+func NewHttpSampler(o ...Option) (*HttpSampler, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	if opts.url == "" {
+		return nil, errors.New("url must be specified")
+	}
+	if opts.extractor == nil {
+		opts.extractor = NewLinesExtractor()
+	}
+	return &HttpSampler{options: opts}, nil
+}
+
+func (s *HttpSampler) Sample(ctx context.Context) (*Set, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unsuccessful response from %s: %d", s.url, resp.StatusCode)
+	}
+	values, err := s.extractor.Extract(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	cids := s.newCidSet()
+	for _, v := range values {
+		c, err := DecodeCidOrMultihash(v)
+		if err != nil {
+			logger.Warnw("Invalid CID extracted from HTTP response", "url", s.url, "value", v, "err", err)
+			continue
+		}
+		cids.Add(c)
+	}
+	if cids.Len() == 0 {
+		logger.Warnw("No CIDs were found in HTTP response", "url", s.url)
+	}
+	return &Set{
+		Cids: cids.Cids(),
+		Name: s.name,
+	}, nil
+}
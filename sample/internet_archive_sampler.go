@@ -37,7 +37,7 @@ func (s *InternetArchiveTopCidsSampler) Sample(ctx context.Context) (*Set, error
 	defer resp.Body.Close()
 	r := csv.NewReader(resp.Body)
 	r.FieldsPerRecord = 1
-	cids := newCidSet()
+	cids := s.newCidSet()
 
 RowsLoop:
 	for {
@@ -58,15 +58,15 @@ RowsLoop:
 					logger.Warnw("Invalid CID from Internet Archive", "value", v, "err", err)
 					continue
 				}
-				cids.putIfAbsent(c)
+				cids.Add(c)
 			}
 		}
 	}
-	if cids.len() == 0 {
+	if cids.Len() == 0 {
 		logger.Warn("No CIDs were found from Internet Archive")
 	}
 	return &Set{
-		Cids: cids.slice(),
+		Cids: cids.Cids(),
 		Name: s.name,
 	}, nil
 }
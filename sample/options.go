@@ -1,16 +1,52 @@
 package sample
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
 
 type (
 	Option  func(*options) error
 	options struct {
-		name string
+		name              string
+		file              string
+		format            Format
+		inline            []string
+		url               string
+		headers           map[string]string
+		extractor         Extractor
+		publisherUrl      string
+		maxAds            int
+		entriesPerAd      int
+		maxChunksPerAd    int
+		adSelection       AdSelection
+		ipniEndpoint      *url.URL
+		providerCount     int
+		providerWeighting ProviderWeighting
+		maxSetSize        int
+		subsampling       Subsampling
+		seed              *int64
+		cacheTTL          time.Duration
+		snapshot          string
+		dedupeByCid       bool
+		composition       Composition
+		sources           []compositeSource
+		pathGateway       *url.URL
 	}
 )
 
 func newOptions(o ...Option) (*options, error) {
-	var opts options
+	opts := options{
+		maxAds:            10,
+		entriesPerAd:      10,
+		maxChunksPerAd:    10,
+		adSelection:       AdSelectionMostRecent,
+		providerCount:     10,
+		providerWeighting: ProviderWeightingUniform,
+		subsampling:       SubsamplingRandom,
+	}
 	for _, apply := range o {
 		if err := apply(&opts); err != nil {
 			return nil, err
@@ -19,12 +55,270 @@ func newOptions(o ...Option) (*options, error) {
 	if opts.name == "" {
 		return nil, errors.New("sample name must be specified")
 	}
+	if opts.ipniEndpoint == nil {
+		var err error
+		if opts.ipniEndpoint, err = url.Parse("https://cid.contact"); err != nil {
+			return nil, err
+		}
+	}
 	return &opts, nil
 }
 
+// Name returns the name of the sampler.
+func (o *options) Name() string {
+	return o.name
+}
+
 func WithName(name string) Option {
 	return func(o *options) error {
 		o.name = name
 		return nil
 	}
 }
+
+// WithFile sets the path to the local file from which CIDs are read.
+func WithFile(path string) Option {
+	return func(o *options) error {
+		o.file = path
+		return nil
+	}
+}
+
+// WithFormat sets the format in which CIDs are listed in the file. When unset, the format is
+// inferred from the file extension, defaulting to FormatText.
+func WithFormat(f Format) Option {
+	return func(o *options) error {
+		o.format = f
+		return nil
+	}
+}
+
+// WithInlineCids sets the list of CIDs or base58 encoded multihashes to sample.
+func WithInlineCids(cids ...string) Option {
+	return func(o *options) error {
+		o.inline = cids
+		return nil
+	}
+}
+
+// WithUrl sets the URL from which CIDs are sampled.
+func WithUrl(u string) Option {
+	return func(o *options) error {
+		o.url = u
+		return nil
+	}
+}
+
+// WithHeaders sets the headers to add to HTTP requests.
+func WithHeaders(h map[string]string) Option {
+	return func(o *options) error {
+		o.headers = h
+		return nil
+	}
+}
+
+// WithExtractor sets the extractor with which CIDs are extracted from HTTP responses.
+// Defaults to NewLinesExtractor.
+func WithExtractor(e Extractor) Option {
+	return func(o *options) error {
+		o.extractor = e
+		return nil
+	}
+}
+
+// WithPublisherUrl sets the URL of the IPNI HTTP publisher from which advertisements are fetched.
+func WithPublisherUrl(u string) Option {
+	return func(o *options) error {
+		o.publisherUrl = u
+		return nil
+	}
+}
+
+// WithMaxAds sets the maximum number of advertisements to walk back from the head advertisement.
+// Defaults to 10.
+func WithMaxAds(m int) Option {
+	return func(o *options) error {
+		if m < 1 {
+			return fmt.Errorf("max ads cannot be less than 1; got %d", m)
+		}
+		o.maxAds = m
+		return nil
+	}
+}
+
+// WithEntriesPerAd sets the maximum number of multihashes to sample from each advertisement.
+// Defaults to 10.
+func WithEntriesPerAd(e int) Option {
+	return func(o *options) error {
+		if e < 1 {
+			return fmt.Errorf("entries per ad cannot be less than 1; got %d", e)
+		}
+		o.entriesPerAd = e
+		return nil
+	}
+}
+
+// WithMaxChunksPerAd sets the maximum number of entry chunks to visit per advertisement.
+// Defaults to 10.
+func WithMaxChunksPerAd(m int) Option {
+	return func(o *options) error {
+		if m < 1 {
+			return fmt.Errorf("max chunks per ad cannot be less than 1; got %d", m)
+		}
+		o.maxChunksPerAd = m
+		return nil
+	}
+}
+
+// WithAdSelection sets the strategy by which multihashes are selected from advertisements.
+// Defaults to AdSelectionMostRecent.
+func WithAdSelection(s AdSelection) Option {
+	return func(o *options) error {
+		switch s {
+		case AdSelectionMostRecent, AdSelectionRandom:
+			o.adSelection = s
+			return nil
+		default:
+			return fmt.Errorf("unknown ad selection: %s", s)
+		}
+	}
+}
+
+// WithIpniEndpoint sets the IPNI endpoint from which providers are listed.
+// Defaults to https://cid.contact.
+func WithIpniEndpoint(endpoint string) Option {
+	return func(o *options) error {
+		var err error
+		o.ipniEndpoint, err = url.Parse(endpoint)
+		return err
+	}
+}
+
+// WithProviderCount sets the number of providers to select at random. Defaults to 10.
+func WithProviderCount(c int) Option {
+	return func(o *options) error {
+		if c < 1 {
+			return fmt.Errorf("provider count cannot be less than 1; got %d", c)
+		}
+		o.providerCount = c
+		return nil
+	}
+}
+
+// WithProviderWeighting sets how providers are weighted when selected at random.
+// Defaults to ProviderWeightingUniform.
+func WithProviderWeighting(w ProviderWeighting) Option {
+	return func(o *options) error {
+		switch w {
+		case ProviderWeightingUniform, ProviderWeightingRecency:
+			o.providerWeighting = w
+			return nil
+		default:
+			return fmt.Errorf("unknown provider weighting: %s", w)
+		}
+	}
+}
+
+// WithMaxSetSize sets the maximum number of CIDs in sample sets, applied by SubsamplingSampler.
+// Sets larger than the maximum are subsampled according to the configured Subsampling strategy.
+// It also sets the total number of CIDs drawn by CompositionMix. Defaults to 0, i.e. unlimited.
+func WithMaxSetSize(s int) Option {
+	return func(o *options) error {
+		if s < 0 {
+			return fmt.Errorf("max set size cannot be negative; got %d", s)
+		}
+		o.maxSetSize = s
+		return nil
+	}
+}
+
+// WithSubsampling sets the strategy by which sample sets larger than the maximum set size are
+// subsampled. Defaults to SubsamplingRandom.
+func WithSubsampling(s Subsampling) Option {
+	return func(o *options) error {
+		switch s {
+		case SubsamplingRandom, SubsamplingFirstN, SubsamplingReservoir:
+			o.subsampling = s
+			return nil
+		default:
+			return fmt.Errorf("unknown subsampling: %s", s)
+		}
+	}
+}
+
+// WithSeed sets the seed used to subsample sample sets at random, so that the same CIDs are
+// selected from the same source. Unseeded by default.
+func WithSeed(seed int64) Option {
+	return func(o *options) error {
+		o.seed = &seed
+		return nil
+	}
+}
+
+// WithCacheTTL sets the duration for which a CachingSampler serves its cached sample set before
+// sampling again.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(o *options) error {
+		if ttl <= 0 {
+			return fmt.Errorf("cache TTL must be positive; got %s", ttl)
+		}
+		o.cacheTTL = ttl
+		return nil
+	}
+}
+
+// WithSnapshot sets the path to the snapshot replayed by ReplaySampler. If the path is a directory,
+// the most recent snapshot in it is replayed.
+func WithSnapshot(path string) Option {
+	return func(o *options) error {
+		o.snapshot = path
+		return nil
+	}
+}
+
+// WithDedupeByCid sets whether to deduplicate sampled CIDs by their full binary form instead of by
+// multihash. Defaults to false, i.e. CIDs with the same multihash are considered duplicates.
+func WithDedupeByCid(b bool) Option {
+	return func(o *options) error {
+		o.dedupeByCid = b
+		return nil
+	}
+}
+
+// WithComposition sets the operation by which CompositeSampler combines its sources.
+func WithComposition(c Composition) Option {
+	return func(o *options) error {
+		o.composition = c
+		return nil
+	}
+}
+
+// WithSource adds a source to CompositeSampler, with the given weight. The weight is only used by
+// CompositionMix, and must not be negative. Sources are combined in the order in which they are
+// added.
+func WithSource(s Sampler, weight float64) Option {
+	return func(o *options) error {
+		if s == nil {
+			return errors.New("source sampler cannot be nil")
+		}
+		if weight < 0 {
+			return fmt.Errorf("source weight cannot be negative; got %f", weight)
+		}
+		o.sources = append(o.sources, compositeSource{sampler: s, weight: weight})
+		return nil
+	}
+}
+
+// WithPathGateway sets the URL of the trustless gateway via which PathResolvingSampler resolves
+// sampled paths to the CIDs of their leaf blocks, which are then sampled along with the root CIDs.
+func WithPathGateway(gateway string) Option {
+	return func(o *options) error {
+		if gateway == "" {
+			o.pathGateway = nil
+			return nil
+		}
+		var err error
+		o.pathGateway, err = url.Parse(gateway)
+		return err
+	}
+}
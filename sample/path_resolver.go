@@ -0,0 +1,128 @@
+package sample
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipni/lookout/perform"
+)
+
+const (
+	// pathResolutionParallelism is the maximum number of concurrent path resolutions.
+	pathResolutionParallelism = 10
+	// pathResolutionTimeout is the timeout for resolving a single path.
+	pathResolutionTimeout = 30 * time.Second
+	// maxCarSectionSize is the maximum size of a CAR section, i.e. a block along with its CID.
+	maxCarSectionSize = maxBlockSize + 1024
+)
+
+// resolvePaths resolves the given paths under their root CIDs to the CIDs of their leaf blocks via
+// the configured trustless gateway. Paths that fail to resolve are skipped. Leaves are returned in
+// no particular order.
+func (o *options) resolvePaths(ctx context.Context, paths map[cid.Cid][]string) []cid.Cid {
+	type rootPath struct {
+		root cid.Cid
+		path string
+	}
+	var targets []rootPath
+	for root, ps := range paths {
+		for _, p := range ps {
+			targets = append(targets, rootPath{root: root, path: p})
+		}
+	}
+	leaves := perform.InParallel(ctx, pathResolutionParallelism, targets, func(ctx context.Context, t rootPath) cid.Cid {
+		leaf, err := o.resolvePath(ctx, t.root, t.path)
+		if err != nil {
+			logger.Warnw("Failed to resolve path to leaf CID", "root", t.root, "path", t.path, "err", err)
+			return cid.Undef
+		}
+		return leaf
+	})
+	var resolved []cid.Cid
+	for leaf := range leaves {
+		if leaf.Defined() {
+			resolved = append(resolved, leaf)
+		}
+	}
+	return resolved
+}
+
+// resolvePath resolves the given path under the given root CID to the CID of its leaf block, by
+// requesting the blocks along the path from the configured trustless gateway as a CAR. The
+// terminal block of the path is the last block in the CAR.
+func (o *options) resolvePath(ctx context.Context, root cid.Cid, p string) (cid.Cid, error) {
+	ctx, cancel := context.WithTimeout(ctx, pathResolutionTimeout)
+	defer cancel()
+	u := o.pathGateway.JoinPath("ipfs", root.String(), strings.Trim(p, "/"))
+	query := u.Query()
+	query.Set("format", "car")
+	query.Set("dag-scope", "block")
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return cid.Undef, err
+	}
+	req.Header.Set("Accept", "application/vnd.ipld.car")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return cid.Undef, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return cid.Undef, fmt.Errorf("unsuccessful response from %s: %d", u, resp.StatusCode)
+	}
+	return lastCarBlock(resp.Body)
+}
+
+// lastCarBlock reads a CARv1 stream and returns the CID of its last block. Block data is
+// discarded without verification, since only the CID is of interest.
+func lastCarBlock(r io.Reader) (cid.Cid, error) {
+	br := bufio.NewReader(r)
+	headerLen, err := binary.ReadUvarint(br)
+	if err != nil {
+		return cid.Undef, fmt.Errorf("failed to read CAR header length: %w", err)
+	}
+	if headerLen > maxCarSectionSize {
+		return cid.Undef, fmt.Errorf("CAR header too large: %d", headerLen)
+	}
+	if _, err := br.Discard(int(headerLen)); err != nil {
+		return cid.Undef, fmt.Errorf("failed to read CAR header: %w", err)
+	}
+	last := cid.Undef
+	section := make([]byte, 0, 1024)
+	for {
+		sectionLen, err := binary.ReadUvarint(br)
+		switch {
+		case errors.Is(err, io.EOF):
+			if !last.Defined() {
+				return cid.Undef, errors.New("no blocks found in CAR")
+			}
+			return last, nil
+		case err != nil:
+			return cid.Undef, fmt.Errorf("failed to read CAR section length: %w", err)
+		case sectionLen > maxCarSectionSize:
+			return cid.Undef, fmt.Errorf("CAR section too large: %d", sectionLen)
+		}
+		section = section[:0]
+		if cap(section) < int(sectionLen) {
+			section = make([]byte, 0, sectionLen)
+		}
+		section = section[:sectionLen]
+		if _, err := io.ReadFull(br, section); err != nil {
+			return cid.Undef, fmt.Errorf("failed to read CAR section: %w", err)
+		}
+		_, c, err := cid.CidFromBytes(section)
+		if err != nil {
+			return cid.Undef, fmt.Errorf("failed to decode CAR section CID: %w", err)
+		}
+		last = c
+	}
+}
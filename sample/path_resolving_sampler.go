@@ -0,0 +1,56 @@
+package sample
+
+import (
+	"context"
+	"errors"
+)
+
+var _ Sampler = (*PathResolvingSampler)(nil)
+
+// PathResolvingSampler wraps a Sampler and resolves the paths of the sample sets it produces to
+// the CIDs of their leaf blocks via the configured trustless gateway. The leaf CIDs are sampled
+// along with the root CIDs. Only the paths present on the set are resolved, so wrapping a
+// SubsamplingSampler resolves the paths of the selected roots only.
+type PathResolvingSampler struct {
+	*options
+	sampler Sampler
+}
+
+func NewPathResolvingSampler(sampler Sampler, o ...Option) (*PathResolvingSampler, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	if opts.pathGateway == nil {
+		return nil, errors.New("path gateway must be specified")
+	}
+	return &PathResolvingSampler{
+		options: opts,
+		sampler: sampler,
+	}, nil
+}
+
+func (s *PathResolvingSampler) Sample(ctx context.Context) (*Set, error) {
+	set, err := s.sampler.Sample(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(set.Paths) == 0 {
+		return set, nil
+	}
+	leaves := s.resolvePaths(ctx, set.Paths)
+	logger.Debugw("Resolved sampled paths", "name", s.name, "paths", len(set.Paths), "leaves", len(leaves))
+	if len(leaves) == 0 {
+		return set, nil
+	}
+	cids := s.newCidSet()
+	for _, c := range set.Cids {
+		cids.Add(c)
+	}
+	for _, leaf := range leaves {
+		cids.Add(leaf)
+	}
+	resolved := *set
+	resolved.Cids = cids.Cids()
+	return &resolved, nil
+}
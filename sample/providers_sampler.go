@@ -0,0 +1,185 @@
+package sample
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipni/lookout/internal/maddr"
+)
+
+var _ Sampler = (*ProvidersSampler)(nil)
+
+const (
+	// ProviderWeightingUniform selects providers uniformly at random.
+	ProviderWeightingUniform ProviderWeighting = "uniform"
+	// ProviderWeightingRecency selects providers at random, weighted towards those that published
+	// an advertisement more recently.
+	ProviderWeightingRecency ProviderWeighting = "recency"
+)
+
+type (
+	// ProviderWeighting represents how providers are weighted when selected at random.
+	ProviderWeighting string
+
+	// ProvidersSampler samples multihashes from the whole index rather than popular content only.
+	// It lists the providers known to an IPNI endpoint via GET /providers, selects a number of
+	// them at random, and samples multihashes from their latest advertisements fetched from their
+	// HTTP publishers.
+	ProvidersSampler struct {
+		*options
+	}
+	providerInfo struct {
+		AddrInfo struct {
+			ID string
+		}
+		LastAdvertisement     cid.Cid
+		LastAdvertisementTime string
+		Publisher             *struct {
+			ID    string
+			Addrs []string
+		}
+	}
+)
+
+func NewProvidersSampler(o ...Option) (*ProvidersSampler, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	return &ProvidersSampler{options: opts}, nil
+}
+
+func (s *ProvidersSampler) Sample(ctx context.Context) (*Set, error) {
+	providers, err := s.listProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cids := s.newCidSet()
+	for _, provider := range s.selectProviders(providers) {
+		logger := logger.With("provider", provider.AddrInfo.ID)
+		publisherUrl, err := provider.publisherUrl()
+		if err != nil {
+			logger.Warnw("Provider has no HTTP publisher; skipping", "err", err)
+			continue
+		}
+		p, err := newPublisherClient(publisherUrl)
+		if err != nil {
+			logger.Warnw("Invalid publisher URL; skipping", "url", publisherUrl, "err", err)
+			continue
+		}
+		// Fetch the head to resolve the path at which the publisher serves advertisements, but
+		// start walking from the last advertisement processed by the indexer.
+		if _, err := p.head(ctx); err != nil {
+			logger.Warnw("Failed to fetch publisher head; skipping", "url", publisherUrl, "err", err)
+			continue
+		}
+		_, mhs, err := s.sampleAdChain(ctx, p, provider.LastAdvertisement)
+		if err != nil {
+			logger.Warnw("Failed to sample provider advertisements; skipping", "url", publisherUrl, "err", err)
+			continue
+		}
+		for _, mh := range mhs {
+			cids.Add(cid.NewCidV1(cid.Raw, mh))
+		}
+	}
+	if cids.Len() == 0 {
+		logger.Warnw("No multihashes were sampled from providers", "endpoint", s.ipniEndpoint)
+	}
+	return &Set{
+		Cids: cids.Cids(),
+		Name: s.name,
+	}, nil
+}
+
+func (s *ProvidersSampler) listProviders(ctx context.Context) ([]*providerInfo, error) {
+	u := s.ipniEndpoint.JoinPath("providers")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unsuccessful response from %s: %d", u, resp.StatusCode)
+	}
+	var providers []*providerInfo
+	if err := json.NewDecoder(resp.Body).Decode(&providers); err != nil {
+		return nil, err
+	}
+	// Only providers with an advertisement can be sampled.
+	sampleable := providers[:0]
+	for _, provider := range providers {
+		if provider.LastAdvertisement.Defined() && provider.Publisher != nil {
+			sampleable = append(sampleable, provider)
+		}
+	}
+	return sampleable, nil
+}
+
+// selectProviders selects up to providerCount providers at random without replacement, weighted
+// according to the configured ProviderWeighting.
+func (s *ProvidersSampler) selectProviders(providers []*providerInfo) []*providerInfo {
+	if len(providers) <= s.providerCount {
+		return providers
+	}
+	// Weighted random sampling without replacement, by Efraimidis and Spirakis: pick the providers
+	// with the largest u^(1/w), where u is uniformly random in (0, 1) and w is the weight.
+	type keyed struct {
+		key      float64
+		provider *providerInfo
+	}
+	now := time.Now()
+	keys := make([]keyed, 0, len(providers))
+	for _, provider := range providers {
+		weight := 1.0
+		if s.providerWeighting == ProviderWeightingRecency {
+			weight = provider.recencyWeight(now)
+		}
+		keys = append(keys, keyed{key: math.Pow(rand.Float64(), 1/weight), provider: provider})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].key > keys[j].key })
+	selected := make([]*providerInfo, 0, s.providerCount)
+	for _, k := range keys[:s.providerCount] {
+		selected = append(selected, k.provider)
+	}
+	return selected
+}
+
+// recencyWeight returns a weight that halves for every day since the last advertisement.
+func (p *providerInfo) recencyWeight(now time.Time) float64 {
+	t, err := time.Parse(time.RFC3339Nano, p.LastAdvertisementTime)
+	if err != nil {
+		return math.SmallestNonzeroFloat32
+	}
+	days := now.Sub(t).Hours() / 24
+	if days < 0 {
+		days = 0
+	}
+	return math.Max(math.Pow(2, -days), math.SmallestNonzeroFloat32)
+}
+
+func (p *providerInfo) publisherUrl() (string, error) {
+	for _, addr := range p.Publisher.Addrs {
+		ma, err := maddr.Parse(addr)
+		if err != nil || !ma.IsHTTP() {
+			continue
+		}
+		u, err := ma.URL()
+		if err != nil {
+			continue
+		}
+		return u.String(), nil
+	}
+	return "", fmt.Errorf("no HTTP publisher address in %v", p.Publisher.Addrs)
+}
@@ -0,0 +1,183 @@
+package sample
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipni/lookout/internal/ad"
+	"github.com/multiformats/go-multihash"
+)
+
+const (
+	// AdSelectionMostRecent samples the first entries of the most recent advertisements.
+	AdSelectionMostRecent AdSelection = "most-recent"
+	// AdSelectionRandom samples entries at random from the most recent advertisements.
+	AdSelectionRandom AdSelection = "random"
+
+	// ipniSyncPath is the path under which IPNI HTTP publishers serve advertisements.
+	ipniSyncPath = "ipni/v1/ad"
+	// maxBlockSize is the maximum size of advertisement and entry chunk blocks.
+	maxBlockSize = 4 << 20
+)
+
+var errHeadNotFound = errors.New("publisher head not found")
+
+type (
+	// AdSelection represents the strategy by which multihashes are selected from advertisements.
+	AdSelection string
+
+	// publisherClient fetches advertisements and entry chunks from an IPNI HTTP publisher.
+	publisherClient struct {
+		url *url.URL
+	}
+)
+
+func newPublisherClient(u string) (*publisherClient, error) {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return nil, err
+	}
+	return &publisherClient{url: pu}, nil
+}
+
+// head fetches the CID of the latest advertisement. If the head is not found at the publisher URL,
+// the IPNI HTTP publisher path under it is tried before giving up.
+func (p *publisherClient) head(ctx context.Context) (cid.Cid, error) {
+	head, err := p.fetchHead(ctx, p.url)
+	if errors.Is(err, errHeadNotFound) && !strings.HasSuffix(strings.TrimSuffix(p.url.Path, "/"), ipniSyncPath) {
+		syncUrl := p.url.JoinPath(ipniSyncPath)
+		if head, err = p.fetchHead(ctx, syncUrl); err == nil {
+			p.url = syncUrl
+		}
+	}
+	return head, err
+}
+
+func (p *publisherClient) fetchHead(ctx context.Context, base *url.URL) (cid.Cid, error) {
+	body, err := p.get(ctx, base.JoinPath("head"))
+	if err != nil {
+		return cid.Undef, err
+	}
+	// Publishers respond with either a signed head in JSON or the head CID as plain text.
+	var signedHead struct {
+		Head cid.Cid `json:"head"`
+	}
+	if err := json.Unmarshal(body, &signedHead); err == nil && signedHead.Head.Defined() {
+		return signedHead.Head, nil
+	}
+	return cid.Decode(string(bytes.TrimSpace(body)))
+}
+
+func (p *publisherClient) advertisement(ctx context.Context, c cid.Cid) (*ad.Advertisement, error) {
+	body, err := p.get(ctx, p.url.JoinPath(c.String()))
+	if err != nil {
+		return nil, err
+	}
+	return ad.DecodeAdvertisement(c, body)
+}
+
+func (p *publisherClient) entryChunk(ctx context.Context, c cid.Cid) (*ad.EntryChunk, error) {
+	body, err := p.get(ctx, p.url.JoinPath(c.String()))
+	if err != nil {
+		return nil, err
+	}
+	return ad.DecodeEntryChunk(c, body)
+}
+
+func (p *publisherClient) get(ctx context.Context, u *url.URL) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound && path.Base(u.Path) == "head":
+		return nil, errHeadNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unsuccessful response from %s: %d", u, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxBlockSize))
+}
+
+// sampleAdChain walks back the advertisement chain from the given head, sampling multihashes from
+// the entries of up to maxAds advertisements. Removal advertisements and advertisements without
+// entries are walked over but not sampled. Returns the ID of the provider of the head
+// advertisement along with the sampled multihashes.
+func (o *options) sampleAdChain(ctx context.Context, p *publisherClient, head cid.Cid) (string, []multihash.Multihash, error) {
+	var provider string
+	var mhs []multihash.Multihash
+	next := head
+	for i := 0; i < o.maxAds && next.Defined(); i++ {
+		a, err := p.advertisement(ctx, next)
+		if err != nil {
+			if i == 0 {
+				return "", nil, err
+			}
+			logger.Warnw("Failed to fetch advertisement; stopping walk", "cid", next, "err", err)
+			break
+		}
+		if provider == "" {
+			provider = a.Provider
+		}
+		if !a.IsRm && a.HasEntries() {
+			sampled, err := o.sampleEntries(ctx, p, a.Entries)
+			if err != nil {
+				logger.Warnw("Failed to sample advertisement entries", "cid", next, "err", err)
+			}
+			mhs = append(mhs, sampled...)
+		}
+		next = cid.Undef
+		if a.PreviousID != nil {
+			next = *a.PreviousID
+		}
+	}
+	return provider, mhs, nil
+}
+
+// sampleEntries samples up to entriesPerAd multihashes from the entry chunk chain starting at the
+// given CID, according to the configured AdSelection.
+func (o *options) sampleEntries(ctx context.Context, p *publisherClient, entries cid.Cid) ([]multihash.Multihash, error) {
+	var sampled []multihash.Multihash
+	var seen int
+	next := entries
+	for chunks := 0; chunks < o.maxChunksPerAd && next.Defined(); chunks++ {
+		ec, err := p.entryChunk(ctx, next)
+		if err != nil {
+			return sampled, err
+		}
+		for _, mh := range ec.Multihashes() {
+			switch {
+			case len(sampled) < o.entriesPerAd:
+				sampled = append(sampled, mh)
+			case o.adSelection == AdSelectionRandom:
+				// Reservoir sampling across all visited chunks.
+				if j := rand.Intn(seen + 1); j < o.entriesPerAd {
+					sampled[j] = mh
+				}
+			}
+			seen++
+		}
+		if o.adSelection == AdSelectionMostRecent && len(sampled) >= o.entriesPerAd {
+			break
+		}
+		next = cid.Undef
+		if ec.Next != nil {
+			next = *ec.Next
+		}
+	}
+	return sampled, nil
+}
@@ -0,0 +1,42 @@
+package sample
+
+import (
+	"context"
+	"errors"
+)
+
+var _ Sampler = (*ReplaySampler)(nil)
+
+// ReplaySampler replays a sample set previously written as a snapshot, so that checks can be
+// re-run against a fixed set of CIDs. The snapshot is re-read on every cycle.
+type ReplaySampler struct {
+	*options
+}
+
+func NewReplaySampler(o ...Option) (*ReplaySampler, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	if opts.snapshot == "" {
+		return nil, errors.New("snapshot must be specified")
+	}
+	return &ReplaySampler{options: opts}, nil
+}
+
+func (s *ReplaySampler) Sample(_ context.Context) (*Set, error) {
+	snapshot, err := ReadSnapshot(s.snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshot.Cids) == 0 {
+		logger.Warnw("No CIDs were found in snapshot", "snapshot", s.snapshot)
+	}
+	return &Set{
+		Cids:      snapshot.Cids,
+		Name:      s.name,
+		Paths:     retainPaths(snapshot.Paths, snapshot.Cids),
+		Provider:  snapshot.Provider,
+		SampledAt: snapshot.Timestamp,
+	}, nil
+}
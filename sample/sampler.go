@@ -2,6 +2,7 @@ package sample
 
 import (
 	"context"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-log/v2"
@@ -16,5 +17,12 @@ type (
 	Set struct {
 		Name string
 		Cids []cid.Cid
+		// Paths are the paths sampled under CIDs of the set, if any, keyed by root CID.
+		Paths map[cid.Cid][]string
+		// Provider is the ID of the provider from which the set was sampled, if any.
+		Provider string
+		// SampledAt is the time at which the set was sampled from its source, if known. It is
+		// set by samplers that may serve previously sampled sets, such as CachingSampler.
+		SampledAt time.Time
 	}
 )
@@ -39,7 +39,8 @@ func (s *SaturnTopCidsSampler) Sample(ctx context.Context) (*Set, error) {
 	if err := json.NewDecoder(resp.Body).Decode(&scids); err != nil {
 		return nil, err
 	}
-	cids := newCidSet()
+	cids := s.newCidSet()
+	paths := make(map[cid.Cid][]string)
 	for _, sc := range scids {
 		cc := strings.SplitN(sc, "/", 2)
 		if len(cc) > 0 {
@@ -48,14 +49,21 @@ func (s *SaturnTopCidsSampler) Sample(ctx context.Context) (*Set, error) {
 				logger.Warnw("Invalid CID from saturn orchestrator", "cid", cc[0], "originalValue", sc, "err", err)
 				continue
 			}
-			cids.putIfAbsent(c)
+			cids.Add(c)
+			if len(cc) > 1 && strings.Trim(cc[1], "/") != "" {
+				// Key paths by the CID kept in the set, which may differ from c when the same
+				// multihash was seen earlier under another CID version or codec.
+				kept, _ := cids.Get(c)
+				paths[kept] = append(paths[kept], cc[1])
+			}
 		}
 	}
-	if cids.len() == 0 {
+	if cids.Len() == 0 {
 		logger.Warn("No CIDs were found from saturn orchestrator")
 	}
 	return &Set{
-		Cids: cids.slice(),
-		Name: s.name,
+		Cids:  cids.Cids(),
+		Name:  s.name,
+		Paths: paths,
 	}, nil
 }
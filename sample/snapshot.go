@@ -0,0 +1,160 @@
+package sample
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-cid"
+)
+
+const (
+	snapshotExtension       = ".json"
+	snapshotTimestampLayout = "20060102T150405.000000000Z"
+)
+
+type (
+	// Snapshot is the persisted form of a sample set, recorded so that checks can later be re-run
+	// against the exact same CIDs.
+	Snapshot struct {
+		Name      string               `json:"name"`
+		Cycle     string               `json:"cycle,omitempty"`
+		Timestamp time.Time            `json:"timestamp"`
+		Provider  string               `json:"provider,omitempty"`
+		Cids      []cid.Cid            `json:"cids"`
+		Paths     map[cid.Cid][]string `json:"paths,omitempty"`
+	}
+)
+
+// WriteSnapshot writes the given sample set as a snapshot under the given directory, and returns
+// the path to the written file. Snapshots are grouped in a sub-directory per sample set name, and
+// named after their timestamp and cycle ID so that they sort chronologically.
+func WriteSnapshot(dir, cycle string, set *Set, timestamp time.Time) (string, error) {
+	setDir := filepath.Join(dir, SnapshotDirName(set.Name))
+	if err := os.MkdirAll(setDir, 0o755); err != nil {
+		return "", err
+	}
+	snapshot := &Snapshot{
+		Name:      set.Name,
+		Cycle:     cycle,
+		Timestamp: timestamp.UTC(),
+		Provider:  set.Provider,
+		Cids:      set.Cids,
+		Paths:     set.Paths,
+	}
+	fileName := snapshot.Timestamp.Format(snapshotTimestampLayout)
+	if cycle != "" {
+		fileName += "-" + url.PathEscape(cycle)
+	}
+	p := filepath.Join(setDir, fileName+snapshotExtension)
+
+	// Write to a temporary file first so that a partially written snapshot is never replayed.
+	tmp, err := os.CreateTemp(setDir, ".snapshot-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if err := json.NewEncoder(tmp).Encode(snapshot); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	return p, os.Rename(tmp.Name(), p)
+}
+
+// ReadSnapshot reads the snapshot at the given path. If the path is a directory, the most recent
+// snapshot in it is read.
+func ReadSnapshot(p string) (*Snapshot, error) {
+	p = filepath.Clean(p)
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		if p, err = latestSnapshot(p); err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var snapshot Snapshot
+	if err := json.NewDecoder(f).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %s: %w", p, err)
+	}
+	return &snapshot, nil
+}
+
+// SnapshotDirName returns the name of the directory under which snapshots of the sample set with
+// the given name are written. Names are escaped, since sampler names commonly contain slashes.
+func SnapshotDirName(name string) string {
+	return url.PathEscape(name)
+}
+
+// PruneSnapshots removes the snapshots of the sample set with the given name under the given
+// directory that exceed the given maximum count, oldest first, or are older than the given maximum
+// age relative to now. A zero maximum count or age disables the corresponding limit. Returns the
+// number of snapshots removed.
+func PruneSnapshots(dir, name string, maxCount int, maxAge time.Duration, now time.Time) (int, error) {
+	setDir := filepath.Join(dir, SnapshotDirName(name))
+	names, err := listSnapshots(setDir)
+	if err != nil {
+		return 0, err
+	}
+	var removed int
+	for i, n := range names {
+		expired := maxCount > 0 && len(names)-i > maxCount
+		if !expired && maxAge > 0 {
+			if ts, err := time.Parse(snapshotTimestampLayout, strings.SplitN(strings.TrimSuffix(n, snapshotExtension), "-", 2)[0]); err == nil {
+				expired = now.Sub(ts) > maxAge
+			}
+		}
+		if !expired {
+			// Snapshots are sorted chronologically, so the remaining ones are within limits.
+			break
+		}
+		if err := os.Remove(filepath.Join(setDir, n)); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func latestSnapshot(dir string) (string, error) {
+	names, err := listSnapshots(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", errors.New("no snapshot found in " + dir)
+	}
+	return filepath.Join(dir, names[len(names)-1]), nil
+}
+
+// listSnapshots lists the names of snapshot files in the given directory in chronological order.
+func listSnapshots(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && !strings.HasPrefix(name, ".") && filepath.Ext(name) == snapshotExtension {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
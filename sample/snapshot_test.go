@@ -0,0 +1,155 @@
+package sample
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+)
+
+func TestWriteSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	a, b := testCid(t, "a"), testCid(t, "b")
+	set := &Set{
+		Cids:     []cid.Cid{a, b},
+		Name:     "example.com/top-cids",
+		Provider: "provider",
+		Paths:    map[cid.Cid][]string{a: {"x/y"}},
+	}
+	ts := time.Date(2023, 5, 1, 12, 30, 0, 42, time.FixedZone("", 3600))
+	p, err := WriteSnapshot(dir, "cycle/1", set, ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(dir, "example.com%2Ftop-cids", "20230501T113000.000000042Z-cycle%2F1.json")
+	if p != want {
+		t.Fatalf("expected snapshot at %s; got %s", want, p)
+	}
+
+	snapshot, err := ReadSnapshot(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Name != set.Name || snapshot.Cycle != "cycle/1" || snapshot.Provider != set.Provider || !snapshot.Timestamp.Equal(ts) {
+		t.Fatalf("unexpected snapshot %+v", snapshot)
+	}
+	assertCids(t, set.Cids, snapshot.Cids)
+	assertPaths(t, set.Paths, snapshot.Paths)
+
+	entries, err := os.ReadDir(filepath.Dir(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected temporary files to be removed; got %d entries", len(entries))
+	}
+}
+
+func TestReadSnapshot_Latest(t *testing.T) {
+	dir := t.TempDir()
+	ts := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i, name := range []string{"b", "c", "a"} {
+		set := &Set{Cids: []cid.Cid{testCid(t, name)}, Name: "set"}
+		if _, err := WriteSnapshot(dir, name, set, ts.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshot, err := ReadSnapshot(filepath.Join(dir, SnapshotDirName("set")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Cycle != "a" {
+		t.Fatalf("expected latest snapshot of cycle a; got %s", snapshot.Cycle)
+	}
+
+	if _, err := ReadSnapshot(t.TempDir()); err == nil {
+		t.Fatal("expected error reading from directory without snapshots")
+	}
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	now := time.Date(2023, 5, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		maxCount    int
+		maxAge      time.Duration
+		wantRemoved int
+		wantCycles  []string
+	}{
+		{name: "no limits", wantCycles: []string{"1", "2", "3", "4"}},
+		{name: "max count", maxCount: 2, wantRemoved: 2, wantCycles: []string{"3", "4"}},
+		{name: "max age", maxAge: 36 * time.Hour, wantRemoved: 2, wantCycles: []string{"3", "4"}},
+		{name: "both", maxCount: 3, maxAge: 60 * time.Hour, wantRemoved: 1, wantCycles: []string{"2", "3", "4"}},
+		{name: "max count beyond", maxCount: 10, wantCycles: []string{"1", "2", "3", "4"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			// Snapshots are 3, 2, 1 and 0 days old; one is written without a cycle ID.
+			for i, cycle := range []string{"1", "2", "3", "4"} {
+				set := &Set{Cids: []cid.Cid{testCid(t, cycle)}, Name: "set"}
+				if cycle == "4" {
+					cycle = ""
+				}
+				if _, err := WriteSnapshot(dir, cycle, set, now.Add(time.Duration(i-3)*24*time.Hour)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			removed, err := PruneSnapshots(dir, "set", test.maxCount, test.maxAge, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if removed != test.wantRemoved {
+				t.Fatalf("expected %d snapshots removed; got %d", test.wantRemoved, removed)
+			}
+			names, err := listSnapshots(filepath.Join(dir, SnapshotDirName("set")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(names) != len(test.wantCycles) {
+				t.Fatalf("expected %d snapshots; got %v", len(test.wantCycles), names)
+			}
+			for i, name := range names {
+				snapshot, err := ReadSnapshot(filepath.Join(dir, SnapshotDirName("set"), name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := test.wantCycles[i]; want != "4" && snapshot.Cycle != want {
+					t.Fatalf("expected snapshot of cycle %s; got %s", want, snapshot.Cycle)
+				}
+			}
+		})
+	}
+}
+
+func TestReplaySampler(t *testing.T) {
+	dir := t.TempDir()
+	a, b := testCid(t, "a"), testCid(t, "b")
+	ts := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	set := &Set{
+		Cids:     []cid.Cid{a},
+		Name:     "original",
+		Provider: "provider",
+		// Paths of CIDs not in the set, e.g. written by an older version, are not replayed.
+		Paths: map[cid.Cid][]string{a: {"x"}, b: {"y"}},
+	}
+	if _, err := WriteSnapshot(dir, "cycle", set, ts); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewReplaySampler(WithName("replay"), WithSnapshot(filepath.Join(dir, SnapshotDirName("original"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := s.Sample(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Name != "replay" || replayed.Provider != "provider" || !replayed.SampledAt.Equal(ts) {
+		t.Fatalf("unexpected replayed set %+v", replayed)
+	}
+	assertCids(t, []cid.Cid{a}, replayed.Cids)
+	assertPaths(t, map[cid.Cid][]string{a: {"x"}}, replayed.Paths)
+}
@@ -0,0 +1,183 @@
+package sample
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+var _ Sampler = (*StaticSampler)(nil)
+
+const (
+	// FormatText represents newline separated values. Empty lines and lines starting with # are
+	// ignored.
+	FormatText Format = "text"
+	// FormatCsv represents comma separated values, where the first column of each record is used.
+	FormatCsv Format = "csv"
+	// FormatJson represents a JSON array of strings.
+	FormatJson Format = "json"
+	// FormatNdjson represents newline delimited JSON strings.
+	FormatNdjson Format = "ndjson"
+)
+
+type (
+	// Format represents the format in which CIDs are listed.
+	Format string
+
+	// StaticSampler samples CIDs or base58 encoded multihashes from a local file and/or an inline
+	// list. The file is re-read on every sample so that changes take effect without restart.
+	StaticSampler struct {
+		*options
+	}
+)
+
+func NewStaticSampler(o ...Option) (*StaticSampler, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	if opts.file == "" && len(opts.inline) == 0 {
+		return nil, errors.New("at least one of file or inline CIDs must be specified")
+	}
+	if opts.format == "" && opts.file != "" {
+		opts.format = formatFromExtension(opts.file)
+	}
+	switch opts.format {
+	case "", FormatText, FormatCsv, FormatJson, FormatNdjson:
+	default:
+		return nil, fmt.Errorf("unknown format: %s", opts.format)
+	}
+	return &StaticSampler{options: opts}, nil
+}
+
+func (s *StaticSampler) Sample(ctx context.Context) (*Set, error) {
+	cids := s.newCidSet()
+	for _, v := range s.inline {
+		c, err := DecodeCidOrMultihash(v)
+		if err != nil {
+			logger.Warnw("Invalid inline CID", "value", v, "err", err)
+			continue
+		}
+		cids.Add(c)
+	}
+	if s.file != "" {
+		values, err := s.readFile()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range values {
+			c, err := DecodeCidOrMultihash(v)
+			if err != nil {
+				logger.Warnw("Invalid CID in file", "file", s.file, "value", v, "err", err)
+				continue
+			}
+			cids.Add(c)
+		}
+	}
+	if cids.Len() == 0 {
+		logger.Warnw("No CIDs were found in static sample", "name", s.name)
+	}
+	return &Set{
+		Cids: cids.Cids(),
+		Name: s.name,
+	}, nil
+}
+
+func (s *StaticSampler) readFile() ([]string, error) {
+	f, err := os.Open(filepath.Clean(s.file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readValues(f, s.format)
+}
+
+// readValues reads string values from the given reader in the given format.
+func readValues(r io.Reader, format Format) ([]string, error) {
+	var values []string
+	switch format {
+	case FormatCsv:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.Comment = '#'
+		for {
+			record, err := cr.Read()
+			if err == io.EOF {
+				return values, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if len(record) > 0 {
+				values = append(values, strings.TrimSpace(record[0]))
+			}
+		}
+	case FormatJson:
+		if err := json.NewDecoder(r).Decode(&values); err != nil {
+			return nil, err
+		}
+		return values, nil
+	case FormatNdjson:
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var v string
+			if err := json.Unmarshal(line, &v); err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, scanner.Err()
+	default:
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			values = append(values, line)
+		}
+		return values, scanner.Err()
+	}
+}
+
+func formatFromExtension(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCsv
+	case ".json":
+		return FormatJson
+	case ".ndjson", ".jsonl":
+		return FormatNdjson
+	default:
+		return FormatText
+	}
+}
+
+// DecodeCidOrMultihash decodes the given string as a CID, falling back on decoding it as a base58
+// encoded multihash, in which case a CIDv1 with raw codec is returned.
+func DecodeCidOrMultihash(v string) (cid.Cid, error) {
+	c, err := cid.Decode(v)
+	if err == nil {
+		return c, nil
+	}
+	mh, mhErr := multihash.FromB58String(v)
+	if mhErr != nil {
+		return cid.Undef, err
+	}
+	return cid.NewCidV1(cid.Raw, mh), nil
+}
@@ -0,0 +1,129 @@
+package sample
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+func TestReadValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		body    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "text", format: FormatText, body: "a\n\n# comment\n b \n", want: []string{"a", "b"}},
+		{name: "default", body: "a\nb", want: []string{"a", "b"}},
+		{name: "csv", format: FormatCsv, body: "a,x\n# comment\n b ,y,z\nc\n", want: []string{"a", "b", "c"}},
+		{name: "csv invalid", format: FormatCsv, body: "\"a\n", wantErr: true},
+		{name: "json", format: FormatJson, body: `["a", "b"]`, want: []string{"a", "b"}},
+		{name: "json not strings", format: FormatJson, body: `[1]`, wantErr: true},
+		{name: "ndjson", format: FormatNdjson, body: "\"a\"\n\n  \"b\"  \n", want: []string{"a", "b"}},
+		{name: "ndjson not string", format: FormatNdjson, body: "\"a\"\n{}\n", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := readValues(strings.NewReader(test.body), test.format)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error; got values %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("expected values %v; got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatFromExtension(t *testing.T) {
+	for path, want := range map[string]Format{
+		"cids.txt":    FormatText,
+		"cids":        FormatText,
+		"cids.CSV":    FormatCsv,
+		"cids.json":   FormatJson,
+		"cids.ndjson": FormatNdjson,
+		"cids.jsonl":  FormatNdjson,
+	} {
+		if got := formatFromExtension(path); got != want {
+			t.Fatalf("expected format %s for %s; got %s", want, path, got)
+		}
+	}
+}
+
+func TestDecodeCidOrMultihash(t *testing.T) {
+	c := testCid(t, "fish")
+	mh := c.Hash()
+	// Base58 encoded SHA2-256 multihashes are valid CIDv0s; other multihashes are not.
+	sha512, err := multihash.Sum([]byte("fish"), multihash.SHA2_512, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		value   string
+		want    cid.Cid
+		wantErr bool
+	}{
+		{value: c.String(), want: c},
+		{value: mh.B58String(), want: cid.NewCidV0(mh)},
+		{value: sha512.B58String(), want: cid.NewCidV1(cid.Raw, sha512)},
+		{value: "fish", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, err := DecodeCidOrMultihash(test.value)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected error; got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equals(test.want) {
+				t.Fatalf("expected %s; got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestStaticSampler(t *testing.T) {
+	a, b, c := testCid(t, "a"), testCid(t, "b"), testCid(t, "c")
+	file := filepath.Join(t.TempDir(), "cids.ndjson")
+	body := `"` + b.String() + `"` + "\n" + `"invalid"` + "\n" + `"` + c.String() + `"` + "\n"
+	if err := os.WriteFile(file, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStaticSampler(WithName("static"), WithFile(file), WithInlineCids(a.String(), "invalid", b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := s.Sample(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertCids(t, []cid.Cid{a, b, c}, set.Cids)
+}
+
+func TestNewStaticSampler_Invalid(t *testing.T) {
+	if _, err := NewStaticSampler(WithName("static")); err == nil {
+		t.Fatal("expected error when neither file nor inline CIDs are specified")
+	}
+	if _, err := NewStaticSampler(WithName("static"), WithInlineCids("a"), WithFormat("yaml")); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
@@ -0,0 +1,64 @@
+package sample
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/ipfs/go-cid"
+)
+
+const (
+	// SubsamplingRandom selects CIDs uniformly at random, in random order.
+	SubsamplingRandom Subsampling = "random"
+	// SubsamplingFirstN selects the first CIDs in the order returned by the source.
+	SubsamplingFirstN Subsampling = "first-n"
+	// SubsamplingReservoir selects CIDs uniformly at random in a single pass over the source,
+	// preserving the relative order of the first selected CIDs.
+	SubsamplingReservoir Subsampling = "reservoir"
+)
+
+// Subsampling represents the strategy by which a sample set is limited to the maximum set size.
+type Subsampling string
+
+// subsample limits the given CIDs to the configured maximum set size, if any, according to the
+// configured Subsampling strategy.
+func (o *options) subsample(cids []cid.Cid) []cid.Cid {
+	if o.maxSetSize <= 0 || len(cids) <= o.maxSetSize {
+		return cids
+	}
+	switch o.subsampling {
+	case SubsamplingFirstN:
+		return cids[:o.maxSetSize]
+	case SubsamplingReservoir:
+		rng := o.newRand()
+		sampled := make([]cid.Cid, o.maxSetSize)
+		copy(sampled, cids)
+		for i := o.maxSetSize; i < len(cids); i++ {
+			if j := rng.Intn(i + 1); j < o.maxSetSize {
+				sampled[j] = cids[i]
+			}
+		}
+		return sampled
+	default:
+		// Partial Fisher-Yates shuffle over a copy so that the source slice is left untouched.
+		rng := o.newRand()
+		shuffled := make([]cid.Cid, len(cids))
+		copy(shuffled, cids)
+		for i := 0; i < o.maxSetSize; i++ {
+			j := i + rng.Intn(len(shuffled)-i)
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		}
+		return shuffled[:o.maxSetSize]
+	}
+}
+
+// newRand instantiates a new source of randomness, seeded with the configured seed if any. A new
+// source is instantiated per sample so that a seeded sampler selects the same CIDs from the same
+// source every cycle.
+func (o *options) newRand() *rand.Rand {
+	seed := time.Now().UnixNano()
+	if o.seed != nil {
+		seed = *o.seed
+	}
+	return rand.New(rand.NewSource(seed))
+}
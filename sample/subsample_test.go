@@ -0,0 +1,110 @@
+package sample
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+)
+
+func TestSubsample(t *testing.T) {
+	cids := make([]cid.Cid, 10)
+	for i := range cids {
+		cids[i] = testCid(t, fmt.Sprint(i))
+	}
+	tests := []struct {
+		name        string
+		subsampling Subsampling
+		maxSetSize  int
+		want        int
+		wantPrefix  bool
+	}{
+		{name: "unbounded", subsampling: SubsamplingRandom, want: 10, wantPrefix: true},
+		{name: "within bound", subsampling: SubsamplingRandom, maxSetSize: 10, want: 10, wantPrefix: true},
+		{name: "first-n", subsampling: SubsamplingFirstN, maxSetSize: 3, want: 3, wantPrefix: true},
+		{name: "random", subsampling: SubsamplingRandom, maxSetSize: 3, want: 3},
+		{name: "default", maxSetSize: 3, want: 3},
+		{name: "reservoir", subsampling: SubsamplingReservoir, maxSetSize: 3, want: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := []Option{WithName("subsample"), WithSeed(1413)}
+			if test.subsampling != "" {
+				opts = append(opts, WithSubsampling(test.subsampling))
+			}
+			if test.maxSetSize != 0 {
+				opts = append(opts, WithMaxSetSize(test.maxSetSize))
+			}
+			o, err := newOptions(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			source := append([]cid.Cid{}, cids...)
+			got := o.subsample(source)
+			if len(got) != test.want {
+				t.Fatalf("expected %d CIDs; got %d", test.want, len(got))
+			}
+			assertCids(t, cids, source)
+			if test.wantPrefix {
+				assertCids(t, cids[:test.want], got)
+			}
+			seen := NewCidSet()
+			for _, c := range got {
+				if !seen.Add(c) {
+					t.Fatalf("expected distinct CIDs; got %s twice", c)
+				}
+			}
+			// Seeded subsampling selects the same CIDs every time.
+			assertCids(t, got, o.subsample(source))
+		})
+	}
+}
+
+func TestSubsamplingSampler(t *testing.T) {
+	a, b, c := testCid(t, "a"), testCid(t, "b"), testCid(t, "c")
+	source := &staticSet{
+		Cids:     []cid.Cid{a, b, c},
+		Name:     "source",
+		Provider: "provider",
+		Paths:    map[cid.Cid][]string{a: {"x"}, c: {"y"}},
+	}
+	tests := []struct {
+		name      string
+		max       int
+		wantCids  []cid.Cid
+		wantPaths map[cid.Cid][]string
+	}{
+		{name: "within bound", max: 3, wantCids: []cid.Cid{a, b, c}, wantPaths: source.Paths},
+		{name: "paths kept", max: 1, wantCids: []cid.Cid{a}, wantPaths: map[cid.Cid][]string{a: {"x"}}},
+		{name: "paths dropped", max: 2, wantCids: []cid.Cid{a, b}, wantPaths: map[cid.Cid][]string{a: {"x"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := NewSubsamplingSampler(source, WithName("subsampled"), WithMaxSetSize(test.max), WithSubsampling(SubsamplingFirstN))
+			if err != nil {
+				t.Fatal(err)
+			}
+			set, err := s.Sample(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertCids(t, test.wantCids, set.Cids)
+			assertPaths(t, test.wantPaths, set.Paths)
+			if set.Provider != source.Provider {
+				t.Fatalf("expected provider %s; got %s", source.Provider, set.Provider)
+			}
+		})
+	}
+}
+
+func TestRetainPaths(t *testing.T) {
+	a, b := testCid(t, "a"), testCid(t, "b")
+	if got := retainPaths(nil, []cid.Cid{a}); got != nil {
+		t.Fatalf("expected no paths; got %v", got)
+	}
+	if got := retainPaths(map[cid.Cid][]string{a: {"x"}}, []cid.Cid{b}); got != nil {
+		t.Fatalf("expected no paths; got %v", got)
+	}
+	assertPaths(t, map[cid.Cid][]string{b: {"y"}}, retainPaths(map[cid.Cid][]string{a: {"x"}, b: {"y"}}, []cid.Cid{b}))
+}
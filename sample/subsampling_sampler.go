@@ -0,0 +1,60 @@
+package sample
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+)
+
+var _ Sampler = (*SubsamplingSampler)(nil)
+
+// SubsamplingSampler wraps a Sampler and limits the sample sets it produces to the configured
+// maximum set size, according to the configured Subsampling strategy. The paths of CIDs that are
+// not selected are dropped from the set.
+type SubsamplingSampler struct {
+	*options
+	sampler Sampler
+}
+
+func NewSubsamplingSampler(sampler Sampler, o ...Option) (*SubsamplingSampler, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	return &SubsamplingSampler{
+		options: opts,
+		sampler: sampler,
+	}, nil
+}
+
+func (s *SubsamplingSampler) Sample(ctx context.Context) (*Set, error) {
+	set, err := s.sampler.Sample(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sampled := s.subsample(set.Cids)
+	if len(sampled) == len(set.Cids) {
+		return set, nil
+	}
+	subset := *set
+	subset.Cids = sampled
+	subset.Paths = retainPaths(set.Paths, sampled)
+	return &subset, nil
+}
+
+// retainPaths returns the paths of the given CIDs only, or nil if none of them have paths.
+func retainPaths(paths map[cid.Cid][]string, cids []cid.Cid) map[cid.Cid][]string {
+	if len(paths) == 0 {
+		return nil
+	}
+	retained := make(map[cid.Cid][]string)
+	for _, c := range cids {
+		if ps, ok := paths[c]; ok {
+			retained[c] = ps
+		}
+	}
+	if len(retained) == 0 {
+		return nil
+	}
+	return retained
+}
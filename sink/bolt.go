@@ -0,0 +1,220 @@
+package sink
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	_ Sink    = (*BoltSink)(nil)
+	_ Querier = (*BoltSink)(nil)
+
+	resultsBucket = []byte("results")
+	// metaBucket holds the record count of resultsBucket under recordCountKey, so that retention
+	// need not walk the records to count them.
+	metaBucket     = []byte("meta")
+	recordCountKey = []byte("recordCount")
+)
+
+// BoltSink stores records in an embedded BoltDB database, keyed by timestamp.
+type BoltSink struct {
+	*options
+	db *bolt.DB
+
+	retentionLock sync.Mutex
+	lastRetention time.Time
+}
+
+func NewBoltSink(o ...Option) (*BoltSink, error) {
+	opts, err := newOptions(o...)
+	if err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(opts.path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		results, err := tx.CreateBucketIfNotExists(resultsBucket)
+		if err != nil {
+			return err
+		}
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+		if meta.Get(recordCountKey) != nil {
+			return nil
+		}
+		// Count the records once for databases written before the count was maintained.
+		return setRecordCount(tx, results.Stats().KeyN)
+	}); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &BoltSink{options: opts, db: db}, nil
+}
+
+func (s *BoltSink) Put(_ context.Context, records ...*Record) error {
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(resultsBucket)
+		for _, record := range records {
+			value, err := marshalRecord(record)
+			if err != nil {
+				return err
+			}
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			if err := b.Put(recordKey(record.Timestamp, seq), value); err != nil {
+				return err
+			}
+		}
+		return setRecordCount(tx, recordCount(tx)+len(records))
+	}); err != nil {
+		return err
+	}
+	return s.enforceRetention()
+}
+
+func (s *BoltSink) Query(ctx context.Context, q *Query) ([]*StoredRecord, error) {
+	var records []*StoredRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(resultsBucket).Cursor()
+		var k, v []byte
+		if q.To.IsZero() {
+			k, v = c.Last()
+		} else {
+			// Seek to the first key at or after To, then step back to the last key before it.
+			if k, _ = c.Seek(recordKey(q.To, 0)); k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+		}
+		for ; k != nil; k, v = c.Prev() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !q.From.IsZero() && keyTime(k).Before(q.From) {
+				break
+			}
+			var record StoredRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				logger.Warnw("Failed to decode stored record; skipping", "err", err)
+				continue
+			}
+			if !q.matches(&record) {
+				continue
+			}
+			records = append(records, &record)
+			if q.Limit > 0 && len(records) >= q.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	return records, err
+}
+
+// enforceRetention deletes records beyond the configured max age and max records, at most once
+// per retention interval.
+func (s *BoltSink) enforceRetention() error {
+	if s.maxAge == 0 && s.maxRecords == 0 {
+		return nil
+	}
+	s.retentionLock.Lock()
+	defer s.retentionLock.Unlock()
+	if time.Since(s.lastRetention) < s.retentionInterval {
+		return nil
+	}
+	s.lastRetention = time.Now()
+
+	var deleted int
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		// Collect keys first; deleting while iterating a cursor may skip keys.
+		var keys [][]byte
+		b := tx.Bucket(resultsBucket)
+		count := recordCount(tx)
+		var excess int
+		if s.maxRecords > 0 {
+			excess = count - s.maxRecords
+		}
+		var cutoff time.Time
+		if s.maxAge > 0 {
+			cutoff = time.Now().Add(-s.maxAge)
+		}
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			if len(keys) >= excess && (cutoff.IsZero() || !keyTime(k).Before(cutoff)) {
+				break
+			}
+			keys = append(keys, append([]byte{}, k...))
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+			deleted++
+		}
+		return setRecordCount(tx, count-deleted)
+	})
+	if deleted > 0 {
+		logger.Infow("Deleted records beyond retention limits", "count", deleted)
+	}
+	return err
+}
+
+func (s *BoltSink) Close() error {
+	return s.db.Close()
+}
+
+// recordCount returns the number of records in resultsBucket.
+func recordCount(tx *bolt.Tx) int {
+	v := tx.Bucket(metaBucket).Get(recordCountKey)
+	if len(v) != 8 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(v))
+}
+
+func setRecordCount(tx *bolt.Tx, count int) error {
+	return tx.Bucket(metaBucket).Put(recordCountKey, binary.BigEndian.AppendUint64(nil, uint64(count)))
+}
+
+func marshalRecord(r *Record) ([]byte, error) {
+	result, err := json.Marshal(r.Result)
+	if err != nil {
+		return nil, err
+	}
+	stored := &StoredRecord{
+		CycleID:   r.CycleID,
+		Sampler:   r.Sampler,
+		Checker:   r.Checker,
+		Timestamp: r.Timestamp,
+		Result:    result,
+	}
+	if r.Result.Multihash != nil {
+		stored.Multihash = r.Result.Multihash.B58String()
+	}
+	return json.Marshal(stored)
+}
+
+// recordKey returns the key of a record as its timestamp in nanoseconds followed by sequence
+// number, both big-endian encoded so that keys are sorted chronologically.
+func recordKey(t time.Time, seq uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:], seq)
+	return key
+}
+
+func keyTime(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key)))
+}
@@ -0,0 +1,138 @@
+package sink
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ipni/lookout/check"
+	"github.com/multiformats/go-multihash"
+)
+
+func TestBoltSink_MaxRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+	s := newTestBoltSink(t, WithPath(path), WithMaxRecords(3), WithRetentionInterval(0))
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		putTestRecord(t, s, &Record{CycleID: string(rune('a' + i)), Timestamp: now.Add(time.Duration(i) * time.Second)})
+	}
+	assertCycles(t, queryTest(t, s, &Query{}), "e", "d", "c")
+
+	// The record count must survive reopening the database.
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s = newTestBoltSink(t, WithPath(path), WithMaxRecords(3), WithRetentionInterval(0))
+	putTestRecord(t, s, &Record{CycleID: "f", Timestamp: now.Add(5 * time.Second)})
+	assertCycles(t, queryTest(t, s, &Query{}), "f", "e", "d")
+}
+
+func TestBoltSink_MaxAge(t *testing.T) {
+	s := newTestBoltSink(t, WithPath(filepath.Join(t.TempDir(), "results.db")), WithMaxAge(time.Hour), WithRetentionInterval(0))
+	now := time.Now()
+	putTestRecord(t, s,
+		&Record{CycleID: "old", Timestamp: now.Add(-3 * time.Hour)},
+		&Record{CycleID: "older", Timestamp: now.Add(-2 * time.Hour)},
+		&Record{CycleID: "recent", Timestamp: now.Add(-time.Minute)})
+	assertCycles(t, queryTest(t, s, &Query{}), "recent")
+}
+
+func TestBoltSink_RetentionInterval(t *testing.T) {
+	s := newTestBoltSink(t, WithPath(filepath.Join(t.TempDir(), "results.db")), WithMaxRecords(1), WithRetentionInterval(time.Hour))
+	now := time.Now()
+	putTestRecord(t, s, &Record{CycleID: "a", Timestamp: now})
+	putTestRecord(t, s, &Record{CycleID: "b", Timestamp: now.Add(time.Second)})
+	// Retention ran on first put only, and is not due again for an hour.
+	assertCycles(t, queryTest(t, s, &Query{}), "b", "a")
+}
+
+func TestBoltSink_Query(t *testing.T) {
+	s := newTestBoltSink(t, WithPath(filepath.Join(t.TempDir(), "results.db")))
+	fish, err := multihash.Sum([]byte("fish"), multihash.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lobster, err := multihash.Sum([]byte("lobster"), multihash.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	putTestRecord(t, s,
+		&Record{CycleID: "c1", Sampler: "s1", Checker: "k1", Timestamp: t0, Result: &check.Result{Multihash: fish}},
+		&Record{CycleID: "c1", Sampler: "s2", Checker: "k1", Timestamp: t0.Add(time.Minute), Result: &check.Result{Multihash: lobster}},
+		&Record{CycleID: "c2", Sampler: "s1", Checker: "k2", Timestamp: t0.Add(2 * time.Minute), Result: &check.Result{Multihash: fish}},
+		&Record{CycleID: "c3", Sampler: "s2", Checker: "k2", Timestamp: t0.Add(3 * time.Minute), Result: &check.Result{Multihash: lobster}})
+
+	tests := []struct {
+		name  string
+		query *Query
+		want  []string
+	}{
+		{name: "all", query: &Query{}, want: []string{"c3", "c2", "c1", "c1"}},
+		{name: "limit", query: &Query{Limit: 2}, want: []string{"c3", "c2"}},
+		{name: "from inclusive", query: &Query{From: t0.Add(2 * time.Minute)}, want: []string{"c3", "c2"}},
+		{name: "to exclusive", query: &Query{To: t0.Add(2 * time.Minute)}, want: []string{"c1", "c1"}},
+		{name: "to after last", query: &Query{To: t0.Add(time.Hour)}, want: []string{"c3", "c2", "c1", "c1"}},
+		{name: "from and to", query: &Query{From: t0.Add(time.Minute), To: t0.Add(3 * time.Minute)}, want: []string{"c2", "c1"}},
+		{name: "from to and limit", query: &Query{From: t0, To: t0.Add(3 * time.Minute), Limit: 1}, want: []string{"c2"}},
+		{name: "cycle", query: &Query{CycleID: "c1"}, want: []string{"c1", "c1"}},
+		{name: "sampler", query: &Query{Sampler: "s1"}, want: []string{"c2", "c1"}},
+		{name: "checker", query: &Query{Checker: "k2"}, want: []string{"c3", "c2"}},
+		{name: "multihash", query: &Query{Multihash: lobster.B58String()}, want: []string{"c3", "c1"}},
+		{name: "filters and limit", query: &Query{Sampler: "s2", Limit: 1}, want: []string{"c3"}},
+		{name: "no match", query: &Query{Sampler: "s1", Checker: "k1", CycleID: "c2"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertCycles(t, queryTest(t, s, test.query), test.want...)
+		})
+	}
+}
+
+func newTestBoltSink(t *testing.T, o ...Option) *BoltSink {
+	t.Helper()
+	s, err := NewBoltSink(o...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func putTestRecord(t *testing.T, s *BoltSink, records ...*Record) {
+	t.Helper()
+	for _, r := range records {
+		if r.Result == nil {
+			r.Result = &check.Result{}
+		}
+	}
+	if err := s.Put(context.Background(), records...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func queryTest(t *testing.T, s *BoltSink, q *Query) []*StoredRecord {
+	t.Helper()
+	records, err := s.Query(context.Background(), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func assertCycles(t *testing.T, records []*StoredRecord, want ...string) {
+	t.Helper()
+	got := make([]string, 0, len(records))
+	for _, r := range records {
+		got = append(got, r.CycleID)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected records of cycles %v; got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected records of cycles %v; got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package sink
+
+import (
+	"errors"
+	"time"
+)
+
+type (
+	Option  func(*options) error
+	options struct {
+		path              string
+		maxAge            time.Duration
+		maxRecords        int
+		retentionInterval time.Duration
+	}
+)
+
+func newOptions(o ...Option) (*options, error) {
+	opts := options{
+		retentionInterval: time.Minute,
+	}
+	for _, apply := range o {
+		if err := apply(&opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.path == "" {
+		return nil, errors.New("sink path must be specified")
+	}
+	return &opts, nil
+}
+
+// WithPath sets the path at which results are stored.
+func WithPath(p string) Option {
+	return func(o *options) error {
+		o.path = p
+		return nil
+	}
+}
+
+// WithMaxAge sets the maximum age of stored records, beyond which they are deleted.
+// Defaults to zero, i.e. records are kept regardless of their age.
+func WithMaxAge(a time.Duration) Option {
+	return func(o *options) error {
+		o.maxAge = a
+		return nil
+	}
+}
+
+// WithMaxRecords sets the maximum number of stored records, beyond which the oldest records are
+// deleted. Defaults to zero, i.e. no limit.
+func WithMaxRecords(m int) Option {
+	return func(o *options) error {
+		if m < 0 {
+			return errors.New("max records cannot be negative")
+		}
+		o.maxRecords = m
+		return nil
+	}
+}
+
+// WithRetentionInterval sets the minimum interval at which retention limits are enforced.
+// Defaults to 1 minute.
+func WithRetentionInterval(i time.Duration) Option {
+	return func(o *options) error {
+		o.retentionInterval = i
+		return nil
+	}
+}
@@ -0,0 +1,78 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ipfs/go-log/v2"
+	"github.com/ipni/lookout/check"
+)
+
+var logger = log.Logger("ipni/lookout/sink")
+
+type (
+	// Sink persists check results beyond the lifetime of a cycle.
+	Sink interface {
+		Put(context.Context, ...*Record) error
+		Close() error
+	}
+	// Querier is optionally implemented by sinks that support reading back stored records.
+	Querier interface {
+		Query(context.Context, *Query) ([]*StoredRecord, error)
+	}
+	// Record is a check result produced as part of a cycle.
+	Record struct {
+		CycleID   string
+		Sampler   string
+		Checker   string
+		Timestamp time.Time
+		Result    *check.Result
+	}
+	// StoredRecord is a record read back from a sink, where the result is represented in its JSON
+	// form.
+	StoredRecord struct {
+		CycleID   string          `json:"cycle"`
+		Sampler   string          `json:"sampler"`
+		Checker   string          `json:"checker"`
+		Timestamp time.Time       `json:"timestamp"`
+		Result    json.RawMessage `json:"result"`
+		// Multihash is the base58 encoded multihash of the result.
+		Multihash string `json:"multihash"`
+	}
+	// Query selects stored records. Zero-valued fields match all records.
+	Query struct {
+		CycleID   string
+		Sampler   string
+		Checker   string
+		Multihash string
+		From      time.Time
+		To        time.Time
+		// Limit is the maximum number of records to return, most recent first.
+		Limit int
+	}
+)
+
+// NewRecords instantiates a record per result in the given results.
+func NewRecords(cycleID string, results *check.Results, timestamp time.Time) []*Record {
+	records := make([]*Record, 0, len(results.Results))
+	for _, result := range results.Results {
+		records = append(records, &Record{
+			CycleID:   cycleID,
+			Sampler:   results.SampleSetName,
+			Checker:   results.CheckerName,
+			Timestamp: timestamp,
+			Result:    result,
+		})
+	}
+	return records
+}
+
+func (q *Query) matches(r *StoredRecord) bool {
+	return (q.CycleID == "" || q.CycleID == r.CycleID) &&
+		(q.Sampler == "" || q.Sampler == r.Sampler) &&
+		(q.Checker == "" || q.Checker == r.Checker) &&
+		(q.Multihash == "" || q.Multihash == r.Multihash) &&
+		(q.From.IsZero() || !r.Timestamp.Before(q.From)) &&
+		(q.To.IsZero() || r.Timestamp.Before(q.To))
+}